@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -63,6 +64,8 @@ func main() {
 	persist := flag.String("persist-path", "./data", "save directory")
 	levelStr := flag.String("log-level", "info", "debug|info|warn|error")
 	solverKind := flag.String("solver", "dlx", "solver to use: dlx|backtrack")
+	uniqueHints := flag.Bool("uniqueness-hints", true, "offer unique rectangle/BUG+1 hints on boards with a single solution")
+	trash := flag.Duration("trash", 7*24*time.Hour, "how long deleted puzzles can be restored; 0 deletes them at once")
 	flag.Parse()
 
 	lvl := slog.LevelInfo
@@ -90,20 +93,32 @@ func main() {
 	g := generator.NewUniqueGenerator(s)
 	v := validator.New()
 	st := storage.NewFS(*persist)
-	hin := hint.NewSingles()
+	st.Trash = *trash
+	hin := hint.NewPipeline()
+	if *uniqueHints {
+		hin.Solver = s
+	}
 	uc := usecase.NewService(s, g, v, hin, st)
 	h := httpadapter.New(uc)
+	// Dailies live in their own folder so they don't show up in the saved-puzzle list.
+	h.Daily = usecase.NewDaily(g, storage.NewFS(filepath.Join(*persist, "daily")))
 
 	tmpl := web.Templates()
 
 	mux := http.NewServeMux()
 	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(web.StaticFS())))
-	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+	// Only the root serves the page, so unknown paths 404 and wrong methods
+	// on API routes get their 405.
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
 		if err := tmpl.ExecuteTemplate(w, "index.tmpl", map[string]any{}); err != nil {
 			http.Error(w, template.HTMLEscapeString(err.Error()), http.StatusInternalServerError)
 		}
 	})
+	// Browsable docs over the spec served at /api/openapi.json.
+	mux.HandleFunc("GET /api/docs", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFileFS(w, r, web.Assets, "static/docs.html")
+	})
 	h.Register(mux)
 
 	srv := &http.Server{
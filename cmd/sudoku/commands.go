@@ -0,0 +1,532 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/generator"
+	"svw.info/sudoku/internal/infrastructure/storage"
+	"svw.info/sudoku/internal/pdf"
+	"svw.info/sudoku/internal/usecase"
+)
+
+// load reads the boards named by the remaining arguments and maps a
+// failure to its exit code.
+func (c *cli) load(names []string) ([]domain.Board, int) {
+	ps, code := c.loadPuzzles(names)
+	boards := make([]domain.Board, len(ps))
+	for i := range ps {
+		boards[i] = ps[i].Board
+	}
+	return boards, code
+}
+
+func (c *cli) loadPuzzles(names []string) ([]domain.Puzzle, int) {
+	ps, err := c.puzzles(names)
+	switch {
+	case errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission):
+		return nil, c.fail(exitError, err)
+	case err != nil:
+		return nil, c.fail(exitInvalid, err)
+	}
+	return ps, exitOK
+}
+
+func interruptible() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt)
+}
+
+func coordList(cs []domain.CellCoord) string {
+	names := make([]string, len(cs))
+	for i, cc := range cs {
+		names[i] = fmt.Sprintf("r%dc%d", cc.Row+1, cc.Col+1)
+	}
+	return strings.Join(names, ", ")
+}
+
+// ---- solve ----
+
+type solveOut struct {
+	Index      int           `json:"index"`
+	Solution   *domain.Board `json:"solution,omitempty"`
+	Unique     bool          `json:"unique"`
+	Nodes      int           `json:"nodes,omitempty"`
+	DurationMs int64         `json:"durationMs"`
+	Error      string        `json:"error,omitempty"`
+}
+
+func (c *cli) solve(args []string) int {
+	fs := c.flags("solve")
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+	boards, code := c.load(fs.Args())
+	if code != exitOK {
+		return code
+	}
+	ctx, stop := interruptible()
+	defer stop()
+	worst := exitOK
+	for i := range boards {
+		worst = max(worst, c.solveOne(ctx, i, &boards[i]))
+	}
+	return worst
+}
+
+func (c *cli) solveOne(ctx context.Context, i int, b *domain.Board) int {
+	out := solveOut{Index: i}
+	report := func(code int, err error) int {
+		out.Error = err.Error()
+		if c.format == "json" {
+			c.emit(nil, out)
+		} else {
+			fmt.Fprintf(c.stderr, "sudoku: puzzle %d: %v\n", i+1, err)
+		}
+		return code
+	}
+	if ok, conflicts, err := c.uc.Validate(ctx, b); err != nil {
+		return report(exitCode(err), err)
+	} else if !ok {
+		return report(exitInvalid, fmt.Errorf("givens conflict at %s", coordList(conflicts)))
+	}
+	sol, st, err := c.uc.Solve(ctx, b)
+	if err != nil {
+		return report(exitCode(err), err)
+	}
+	unique, _, err := c.uc.Solver.Unique(ctx, b)
+	if err != nil {
+		return report(exitError, err)
+	}
+	out.Solution, out.Unique, out.Nodes, out.DurationMs = sol, unique, st.Nodes, st.Duration.Milliseconds()
+	c.emit(sol, out)
+	if !unique {
+		if c.format != "json" {
+			fmt.Fprintf(c.stderr, "sudoku: puzzle %d: more than one solution; printed one of them\n", i+1)
+		}
+		return exitNotUnique
+	}
+	return exitOK
+}
+
+// ---- generate ----
+
+type generateOut struct {
+	Seed       int64        `json:"seed"`
+	Difficulty string       `json:"difficulty"`
+	Board      domain.Board `json:"board"`
+}
+
+func (c *cli) generate(args []string) int {
+	fs := c.flags("generate")
+	diff := fs.String("difficulty", "medium", "easy|medium|hard|expert")
+	seed := fs.Int64("seed", 0, "seed of the first puzzle; 0 picks one from the clock")
+	count := fs.Int("count", 1, "number of puzzles; puzzle i uses seed+i")
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+	d, ok := parseDifficulty(*diff)
+	if !ok || *count < 1 || fs.NArg() > 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	ctx, stop := interruptible()
+	defer stop()
+	for i := 0; i < *count; i++ {
+		s := *seed + int64(i)
+		p, _, err := c.uc.Generate(ctx, s, d)
+		if err != nil {
+			return c.fail(exitError, err)
+		}
+		c.emit(&p.Board, generateOut{Seed: s, Difficulty: d.String(), Board: p.Board})
+	}
+	return exitOK
+}
+
+func parseDifficulty(s string) (domain.Difficulty, bool) {
+	for d := domain.Easy; d <= domain.Expert; d++ {
+		if strings.EqualFold(s, d.String()) {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// ---- validate ----
+
+type validateOut struct {
+	Index     int                `json:"index"`
+	Valid     bool               `json:"valid"`
+	Conflicts []domain.CellCoord `json:"conflicts,omitempty"`
+	Solvable  bool               `json:"solvable"`
+	Unique    bool               `json:"unique"`
+}
+
+func (c *cli) validate(args []string) int {
+	fs := c.flags("validate")
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+	boards, code := c.load(fs.Args())
+	if code != exitOK {
+		return code
+	}
+	ctx, stop := interruptible()
+	defer stop()
+	worst := exitOK
+	for i := range boards {
+		b := &boards[i]
+		out := validateOut{Index: i}
+		ok, conflicts, err := c.uc.Validate(ctx, b)
+		if err != nil {
+			return c.fail(exitCode(err), err)
+		}
+		out.Valid, out.Conflicts = ok, conflicts
+		verdict, code := "", exitOK
+		if !ok {
+			verdict, code = "conflicts at "+coordList(conflicts), exitInvalid
+		} else if _, _, err := c.uc.Solve(ctx, b); errors.Is(err, domain.ErrUnsolvable) {
+			verdict, code = "no solution", exitUnsolvable
+		} else if err != nil {
+			return c.fail(exitCode(err), err)
+		} else {
+			out.Solvable = true
+			if out.Unique, _, err = c.uc.Solver.Unique(ctx, b); err != nil {
+				return c.fail(exitError, err)
+			}
+			verdict = "ok, unique solution"
+			if !out.Unique {
+				verdict, code = "more than one solution", exitNotUnique
+			}
+		}
+		if c.format == "json" {
+			c.emit(nil, out)
+		} else {
+			fmt.Fprintf(c.stdout, "puzzle %d: %s\n", i+1, verdict)
+		}
+		worst = max(worst, code)
+	}
+	return worst
+}
+
+// ---- hint ----
+
+type hintOut struct {
+	Index int          `json:"index"`
+	Found bool         `json:"found"`
+	Hint  *domain.Hint `json:"hint,omitempty"`
+}
+
+func (c *cli) hint(args []string) int {
+	fs := c.flags("hint")
+	tier := fs.String("max-tier", domain.StrategyUniqueness.String(), "hardest strategy tier to use")
+	fallback := fs.Bool("fallback", false, "fall back to a forcing chain or revealed digit")
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+	maxTier, ok := domain.ParseStrategyTier(*tier)
+	if !ok {
+		fmt.Fprintf(c.stderr, "sudoku: unknown tier %q\n", *tier)
+		return exitUsage
+	}
+	boards, code := c.load(fs.Args())
+	if code != exitOK {
+		return code
+	}
+	ctx, stop := interruptible()
+	defer stop()
+	worst := exitOK
+	for i := range boards {
+		b := &boards[i]
+		if ok, conflicts, err := c.uc.Validate(ctx, b); err != nil {
+			fmt.Fprintf(c.stderr, "sudoku: puzzle %d: %v\n", i+1, err)
+			worst = max(worst, exitCode(err))
+			continue
+		} else if !ok {
+			fmt.Fprintf(c.stderr, "sudoku: puzzle %d: givens conflict at %s\n", i+1, coordList(conflicts))
+			worst = max(worst, exitInvalid)
+			continue
+		}
+		hh, found, err := c.uc.Hint(ctx, b, maxTier)
+		if err == nil && !found && *fallback {
+			hh, found, err = c.uc.LastResort(ctx, b, nil)
+		}
+		if err != nil {
+			fmt.Fprintf(c.stderr, "sudoku: puzzle %d: %v\n", i+1, err)
+			worst = max(worst, exitCode(err))
+			continue
+		}
+		switch {
+		case c.format == "json":
+			out := hintOut{Index: i, Found: found}
+			if found {
+				out.Hint = &hh
+			}
+			c.emit(nil, out)
+		case found:
+			fmt.Fprintf(c.stdout, "puzzle %d: %s: %s\n", i+1, hh.Technique, hh.Message)
+		default:
+			fmt.Fprintf(c.stdout, "puzzle %d: no hint up to tier %s\n", i+1, maxTier)
+		}
+	}
+	return worst
+}
+
+// ---- rate ----
+
+type rateOut struct {
+	Index    int    `json:"index"`
+	TierName string `json:"tierName"`
+	generator.Rating
+}
+
+func (c *cli) rate(args []string) int {
+	fs := c.flags("rate")
+	tier := fs.String("max-tier", domain.StrategyUniqueness.String(), "hardest strategy tier to use")
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+	maxTier, ok := domain.ParseStrategyTier(*tier)
+	if !ok {
+		fmt.Fprintf(c.stderr, "sudoku: unknown tier %q\n", *tier)
+		return exitUsage
+	}
+	boards, code := c.load(fs.Args())
+	if code != exitOK {
+		return code
+	}
+	ctx, stop := interruptible()
+	defer stop()
+	worst := exitOK
+	for i := range boards {
+		b := &boards[i]
+		if ok, conflicts, err := c.uc.Validate(ctx, b); err != nil {
+			fmt.Fprintf(c.stderr, "sudoku: puzzle %d: %v\n", i+1, err)
+			worst = max(worst, exitCode(err))
+			continue
+		} else if !ok {
+			fmt.Fprintf(c.stderr, "sudoku: puzzle %d: givens conflict at %s\n", i+1, coordList(conflicts))
+			worst = max(worst, exitInvalid)
+			continue
+		}
+		rt, err := generator.Rate(ctx, c.uc.Hinter, b, maxTier)
+		if err != nil {
+			return c.fail(exitError, err)
+		}
+		if c.format == "json" {
+			c.emit(nil, rateOut{Index: i, TierName: rt.Tier.String(), Rating: rt})
+			continue
+		}
+		verdict := "solved"
+		if !rt.Solved {
+			verdict = "stuck"
+		}
+		fmt.Fprintf(c.stdout, "puzzle %d: tier %s, %d steps, %s (%s)\n", i+1, rt.Tier, rt.Steps, verdict, techniqueList(rt.Techniques))
+	}
+	return worst
+}
+
+// techniqueList renders counts as "naked single×40, XY-Wing×2", most used first.
+func techniqueList(m map[string]int) string {
+	names := make([]string, 0, len(m))
+	for k := range m {
+		names = append(names, k)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if m[names[i]] != m[names[j]] {
+			return m[names[i]] > m[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	for i, n := range names {
+		names[i] = fmt.Sprintf("%s×%d", n, m[n])
+	}
+	return strings.Join(names, ", ")
+}
+
+// ---- convert ----
+
+func (c *cli) convert(args []string) int {
+	fs := c.flags("convert")
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+	ps, code := c.loadPuzzles(fs.Args())
+	if code != exitOK {
+		return code
+	}
+	for i := range ps {
+		c.emitPuzzle(&ps[i], ps[i])
+	}
+	return exitOK
+}
+
+// ---- import ----
+
+func (c *cli) importCollection(args []string) int {
+	fs := c.flags("import")
+	collection := fs.String("collection", "", "collection name stored on every puzzle (required)")
+	tags := fs.String("tags", "", "comma-separated tags stored on every puzzle")
+	data := fs.String("data", "./data", "puzzle store directory, as the server's -persist-path")
+	tier := fs.String("max-tier", domain.StrategyUniqueness.String(), "hardest strategy tier used to rate")
+	checkpoint := fs.String("checkpoint", "", "progress file; defaults to FILE.import.json when reading a file")
+	restart := fs.Bool("restart", false, "ignore an unfinished checkpoint and start over")
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+	maxTier, ok := domain.ParseStrategyTier(*tier)
+	if !ok || *collection == "" || fs.NArg() > 1 {
+		fs.Usage()
+		return exitUsage
+	}
+	c.uc.Storage = storage.NewFS(*data)
+	opt := usecase.CollectionOptions{Collection: *collection, MaxTier: maxTier}
+	for _, t := range strings.Split(*tags, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			opt.Tags = append(opt.Tags, t)
+		}
+	}
+
+	var in io.Reader = c.stdin
+	if name := fs.Arg(0); name != "" && name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return c.fail(exitError, err)
+		}
+		defer f.Close()
+		in = f
+		if *checkpoint == "" {
+			*checkpoint = name + ".import.json"
+		}
+	}
+	if *checkpoint != "" {
+		if prev, err := readCheckpoint(*checkpoint); err == nil && !prev.Done && prev.Collection == *collection && !*restart {
+			opt.Resume = prev
+			fmt.Fprintf(c.stderr, "sudoku: resuming after line %d\n", prev.Line)
+		}
+		opt.Checkpoint = func(rep *usecase.ImportReport) error {
+			return writeCheckpoint(*checkpoint, rep)
+		}
+	}
+
+	ctx, stop := interruptible()
+	defer stop()
+	rep, err := c.uc.ImportCollection(ctx, in, opt)
+	if rep == nil {
+		return c.fail(exitError, err)
+	}
+	if c.format == "json" {
+		c.emit(nil, rep)
+	} else {
+		for _, is := range rep.Rejected {
+			fmt.Fprintf(c.stdout, "line %d: rejected: %s\n", is.Line, is.Reason)
+		}
+		for _, is := range rep.Duplicates {
+			fmt.Fprintf(c.stdout, "line %d: duplicate: %s\n", is.Line, is.Reason)
+		}
+		fmt.Fprintf(c.stdout, "%s: %d imported, %d rejected, %d duplicates\n", rep.Collection, rep.Imported, len(rep.Rejected), len(rep.Duplicates))
+	}
+	if err != nil {
+		if *checkpoint != "" {
+			fmt.Fprintf(c.stderr, "sudoku: stopped after line %d; run again to resume\n", rep.Line)
+		}
+		return c.fail(exitError, err)
+	}
+	return exitOK
+}
+
+func readCheckpoint(path string) (*usecase.ImportReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rep usecase.ImportReport
+	if err := json.Unmarshal(data, &rep); err != nil {
+		return nil, err
+	}
+	return &rep, nil
+}
+
+// writeCheckpoint replaces path through a rename so an interruption never
+// leaves a truncated file behind.
+func writeCheckpoint(path string, rep *usecase.ImportReport) error {
+	data, err := json.MarshalIndent(rep, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// ---- print ----
+
+func (c *cli) print(args []string) int {
+	fs := c.flags("print")
+	perPage := fs.Int("per-page", 4, fmt.Sprintf("puzzles per page, 1-%d", pdf.MaxPerPage))
+	cands := fs.Bool("candidates", false, "pencil in the candidates of empty cells")
+	title := fs.String("title", "", "heading printed on every page")
+	out := fs.String("out", "-", "PDF file to write; - for stdout")
+	ids := fs.String("ids", "", "comma-separated IDs of stored puzzles to print instead of files")
+	data := fs.String("data", "./data", "puzzle store directory used with --ids")
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+	if *perPage < 1 || *perPage > pdf.MaxPerPage || (*ids != "" && fs.NArg() > 0) {
+		fs.Usage()
+		return exitUsage
+	}
+	opt := pdf.Options{Title: *title, PerPage: *perPage, Candidates: *cands}
+	ctx, stop := interruptible()
+	defer stop()
+
+	var buf bytes.Buffer
+	if *ids != "" {
+		c.uc.Storage = storage.NewFS(*data)
+		if err := c.uc.Print(ctx, strings.Split(*ids, ","), opt, &buf); err != nil {
+			return c.fail(exitError, err)
+		}
+	} else {
+		ps, code := c.loadPuzzles(fs.Args())
+		if code != exitOK {
+			return code
+		}
+		// Text formats carry no difficulty; label each puzzle by its rating.
+		for i := range ps {
+			rt, err := generator.Rate(ctx, c.uc.Hinter, &ps[i].Board, domain.StrategyUniqueness)
+			if err != nil {
+				return c.fail(exitError, err)
+			}
+			ps[i].Difficulty = rt.Difficulty()
+		}
+		if err := c.uc.PrintPuzzles(ctx, ps, opt, &buf); err != nil {
+			return c.fail(exitError, err)
+		}
+	}
+	if *out == "-" {
+		_, err := buf.WriteTo(c.stdout)
+		if err != nil {
+			return c.fail(exitError, err)
+		}
+		return exitOK
+	}
+	if err := os.WriteFile(*out, buf.Bytes(), 0o644); err != nil {
+		return c.fail(exitError, err)
+	}
+	return exitOK
+}
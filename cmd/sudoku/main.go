@@ -0,0 +1,214 @@
+// Command sudoku solves, generates, checks, hints and rates puzzles from the
+// command line, using the same use cases as the web server.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"svw.info/sudoku/internal/codec"
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/generator"
+	"svw.info/sudoku/internal/hint"
+	"svw.info/sudoku/internal/ports"
+	"svw.info/sudoku/internal/solver"
+	"svw.info/sudoku/internal/usecase"
+	"svw.info/sudoku/internal/validator"
+)
+
+// Exit codes. When several puzzles are processed the highest one wins.
+const (
+	exitOK         = 0
+	exitError      = 1 // I/O or internal error
+	exitUsage      = 2 // bad arguments
+	exitInvalid    = 3 // unreadable input or conflicting givens
+	exitUnsolvable = 4
+	exitNotUnique  = 5
+)
+
+const usage = `usage: sudoku <command> [flags] [file ...]
+
+Commands:
+  solve      solve each puzzle
+  generate   generate puzzles (--difficulty, --seed, --count)
+  validate   check givens for conflicts, solvability and uniqueness
+  hint       print the next logical step
+  rate       grade each puzzle by the strategies it needs
+  convert    re-print puzzles in another format
+  import     add a collection to the puzzle store (--collection, --data)
+  print      write a PDF booklet with an answer key (--per-page, --out)
+
+Puzzles are read from the files, or stdin when none (or "-") is given, in
+any of the text formats: 81-character lines, .sdm, .sdk, .ss, pencil-mark
+grids or 9-row grids. Output is chosen with --format
+grid|line|sdm|sdk|ss|pm|json. Run "sudoku <command> -h" for the flags of a
+command.
+
+Exit codes: 0 ok, 1 error, 2 usage, 3 invalid input, 4 unsolvable,
+5 not unique.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// cli carries the streams, common flags and wiring shared by the commands.
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	format     string
+	solverKind string
+	uc         *usecase.Service
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+	c := &cli{stdin: stdin, stdout: stdout, stderr: stderr}
+	switch args[0] {
+	case "solve":
+		return c.solve(args[1:])
+	case "generate":
+		return c.generate(args[1:])
+	case "validate":
+		return c.validate(args[1:])
+	case "hint":
+		return c.hint(args[1:])
+	case "rate":
+		return c.rate(args[1:])
+	case "convert":
+		return c.convert(args[1:])
+	case "import":
+		return c.importCollection(args[1:])
+	case "print":
+		return c.print(args[1:])
+	case "-h", "--help", "help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "sudoku: unknown command %q\n\n%s", args[0], usage)
+		return exitUsage
+	}
+}
+
+// flags returns a flag set with the options every command accepts.
+func (c *cli) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("sudoku "+name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.StringVar(&c.format, "format", "grid", "output format: grid|line|sdm|sdk|ss|pm|json")
+	fs.StringVar(&c.solverKind, "solver", "dlx", "solver to use: dlx|backtrack")
+	return fs
+}
+
+// parse parses args and wires the use cases. ok is false when the command
+// should exit with code.
+func (c *cli) parse(fs *flag.FlagSet, args []string) (code int, ok bool) {
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK, false
+		}
+		return exitUsage, false
+	}
+	if _, ok := codec.ParseFormat(c.format); !ok && c.format != "json" {
+		fmt.Fprintf(c.stderr, "sudoku: unknown format %q\n", c.format)
+		return exitUsage, false
+	}
+	var s ports.Solver
+	switch strings.ToLower(strings.TrimSpace(c.solverKind)) {
+	case "backtrack", "backtracking":
+		s = solver.NewBacktrackingSolver()
+	default:
+		s = solver.NewDLXSolver()
+	}
+	hin := hint.NewPipeline()
+	hin.Solver = s
+	c.uc = usecase.NewService(s, generator.NewUniqueGenerator(s), validator.New(), hin, nil)
+	return exitOK, true
+}
+
+// puzzles reads every puzzle from the named files, or stdin.
+func (c *cli) puzzles(names []string) ([]domain.Puzzle, error) {
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+	var out []domain.Puzzle
+	for _, name := range names {
+		ps, err := c.decodeFile(name)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ps...)
+	}
+	return out, nil
+}
+
+// decodeFile reads the puzzles of one file, or stdin for "-", and closes
+// the file before returning.
+func (c *cli) decodeFile(name string) ([]domain.Puzzle, error) {
+	if name == "-" {
+		ps, _, err := codec.Decode(c.stdin)
+		return ps, err
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	ps, _, err := codec.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return ps, nil
+}
+
+// emit writes a board in the chosen format; v is written instead in JSON,
+// where b may be nil.
+func (c *cli) emit(b *domain.Board, v any) {
+	p := &domain.Puzzle{}
+	if b != nil {
+		p.Board = *b
+	}
+	c.emitPuzzle(p, v)
+}
+
+// emitPuzzle is emit for a puzzle whose name, notes and marks the text
+// format may carry. Multi-line formats end each puzzle with a blank line.
+func (c *cli) emitPuzzle(p *domain.Puzzle, v any) {
+	if c.format == "json" {
+		enc := json.NewEncoder(c.stdout)
+		_ = enc.Encode(v)
+		return
+	}
+	f, _ := codec.ParseFormat(c.format)
+	_ = codec.Encode(c.stdout, f, []domain.Puzzle{*p})
+	if f.Multiline() {
+		fmt.Fprintln(c.stdout)
+	}
+}
+
+// fail reports err and returns code.
+func (c *cli) fail(code int, err error) int {
+	fmt.Fprintf(c.stderr, "sudoku: %v\n", err)
+	return code
+}
+
+// exitCode is the exit code for an error of the use cases, by its kind.
+func exitCode(err error) int {
+	switch domain.KindOf(err) {
+	case domain.KindInvalid:
+		return exitInvalid
+	case domain.KindUnsolvable:
+		return exitUnsolvable
+	case domain.KindNotUnique:
+		return exitNotUnique
+	}
+	return exitError
+}
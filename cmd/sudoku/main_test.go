@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	classic   = "53..7....6..195....98....6.8...6...34..8.3..17...2...6.6....28....419..5....8..79"
+	solved    = "534678912672195348198342567859761423426853791713924856961537284287419635345286179"
+	conflict  = "55..7....6..195....98....6.8...6...34..8.3..17...2...6.6....28....419..5....8..79"
+	stuck     = "12345678.........9..............................................................."
+	openBoard = "................................................................................."
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "classic.txt")
+	if err := os.WriteFile(file, []byte(classic+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name   string
+		args   []string
+		stdin  string
+		code   int
+		stdout string // substring of stdout
+		stderr string // substring of stderr
+	}{
+		{name: "no command", code: exitUsage, stderr: "usage:"},
+		{name: "unknown command", args: []string{"frobnicate"}, code: exitUsage, stderr: `unknown command "frobnicate"`},
+		{name: "unknown flag", args: []string{"solve", "--nope"}, code: exitUsage},
+		{name: "unknown format", args: []string{"solve", "--format", "xml"}, code: exitUsage, stderr: `unknown format "xml"`},
+		{name: "unknown tier", args: []string{"hint", "--max-tier", "swordfish"}, code: exitUsage, stderr: `unknown tier "swordfish"`},
+		{name: "solve stdin", args: []string{"solve", "--format", "line"}, stdin: classic, stdout: solved},
+		{name: "solve stdin dash", args: []string{"solve", "--format", "line", "-"}, stdin: classic, stdout: solved},
+		{name: "solve file", args: []string{"solve", "--format", "line", file}, stdout: solved},
+		{name: "solve json", args: []string{"solve", "--format", "json", file}, stdout: `"unique":true`},
+		{name: "missing file", args: []string{"solve", filepath.Join(dir, "missing.txt")}, code: exitError, stderr: "missing.txt"},
+		{name: "unreadable input", args: []string{"solve"}, stdin: "123\n", code: exitInvalid},
+		{name: "conflicting givens", args: []string{"solve"}, stdin: conflict, code: exitInvalid, stderr: "givens conflict at"},
+		{name: "validate ok", args: []string{"validate", file}, stdout: "puzzle 1: ok, unique solution"},
+		{name: "validate conflict", args: []string{"validate"}, stdin: conflict, code: exitInvalid, stdout: "conflicts at"},
+		{name: "validate unsolvable", args: []string{"validate"}, stdin: stuck, code: exitUnsolvable, stdout: "no solution"},
+		{name: "validate not unique", args: []string{"validate"}, stdin: openBoard, code: exitNotUnique, stdout: "more than one solution"},
+		{name: "validate json", args: []string{"validate", "--format", "json"}, stdin: openBoard, code: exitNotUnique, stdout: `"unique":false`},
+		{name: "solve unsolvable", args: []string{"solve"}, stdin: stuck, code: exitUnsolvable},
+		{name: "solve not unique", args: []string{"solve", "--format", "line"}, stdin: openBoard, code: exitNotUnique, stderr: "more than one solution"},
+		{name: "worst code wins", args: []string{"validate", file, "-"}, stdin: stuck + "\n" + openBoard, code: exitNotUnique},
+		{name: "hint", args: []string{"hint", file}, stdout: "puzzle 1: "},
+		{name: "hint json", args: []string{"hint", "--format", "json", file}, stdout: `"found":true`},
+		{name: "hint conflict", args: []string{"hint"}, stdin: conflict, code: exitInvalid, stderr: "givens conflict at"},
+		{name: "rate json", args: []string{"rate", "--format", "json", file}, stdout: `"tierName":`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tc.args, strings.NewReader(tc.stdin), &stdout, &stderr)
+			if code != tc.code {
+				t.Errorf("exit code %d, want %d\nstdout: %s\nstderr: %s", code, tc.code, &stdout, &stderr)
+			}
+			if !strings.Contains(stdout.String(), tc.stdout) {
+				t.Errorf("stdout %q, want it to contain %q", &stdout, tc.stdout)
+			}
+			if !strings.Contains(stderr.String(), tc.stderr) {
+				t.Errorf("stderr %q, want it to contain %q", &stderr, tc.stderr)
+			}
+		})
+	}
+}
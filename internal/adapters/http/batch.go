@@ -0,0 +1,116 @@
+package httpadapter
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"svw.info/sudoku/internal/codec"
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/usecase"
+)
+
+// ---- Batch ----
+
+type batchReq struct {
+	Boards [][9][9]uint8 `json:"boards"`
+}
+
+const maxBatchBody = 64 << 20
+
+// handleBatch takes {"boards":[...]} as JSON, or any other body as one
+// 81-character puzzle per line ("0" or "." for empty cells), and streams one
+// usecase.BatchResult per puzzle back as NDJSON in completion order.
+// ?workers= lowers the pool size; ?maxTier= caps the rating strategies.
+func (h *Handler) handleBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		fail(w, errMethod)
+		return
+	}
+	q := r.URL.Query()
+	workers := usecase.BatchWorkers()
+	if n, err := strconv.Atoi(q.Get("workers")); err == nil && n > 0 && n < workers {
+		workers = n
+	}
+	maxTier, err := parseTier(q.Get("maxTier"), domain.StrategyUniqueness)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxBatchBody)
+	items := make(chan usecase.BatchItem)
+	readErr := make(chan error, 1)
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		var req batchReq
+		if err := json.NewDecoder(body).Decode(&req); err != nil {
+			fail(w, badJSON(err))
+			return
+		}
+		go func() {
+			defer close(items)
+			for _, b := range req.Boards {
+				select {
+				case items <- usecase.BatchItem{Board: domain.Board{Values: b}}:
+				case <-r.Context().Done():
+					return
+				}
+			}
+			readErr <- nil
+		}()
+	} else {
+		go func() {
+			defer close(items)
+			sc := bufio.NewScanner(body)
+			line := 0
+			for sc.Scan() {
+				line++
+				text := strings.TrimSpace(sc.Text())
+				if text == "" {
+					continue
+				}
+				it := usecase.BatchItem{}
+				it.Board, it.Err = codec.ParseLine(text)
+				var ce *codec.Error
+				if errors.As(it.Err, &ce) {
+					ce.Line = line
+				} else if it.Err != nil {
+					it.Err = fmt.Errorf("line %d: %w", line, it.Err)
+				}
+				select {
+				case items <- it:
+				case <-r.Context().Done():
+					return
+				}
+			}
+			readErr <- sc.Err()
+		}()
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	err = h.UC.Batch(r.Context(), items, workers, maxTier, func(res usecase.BatchResult) error {
+		if err := enc.Encode(res); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err == nil {
+		select {
+		case err = <-readErr:
+		default:
+		}
+	}
+	if err != nil {
+		resp, _ := newErrorResp(err)
+		_ = enc.Encode(resp)
+	}
+}
@@ -0,0 +1,82 @@
+package httpadapter
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/usecase"
+)
+
+// ---- Collections ----
+
+// handleCollectionImport bulk-imports the collection in the body into the
+// collection named in the path, or ?collection=, with the comma-separated
+// ?tags=. It streams the
+// usecase.ImportReport as NDJSON after every checkpoint, each line listing
+// only the rejected and duplicate puzzles found since the one before; the
+// last line has "done":true, or an "error" when the import stopped. An
+// interrupted import resumes by posting the same body again with ?after= and
+// ?imported= set to the "line" and "imported" of the last report received.
+func (h *Handler) handleCollectionImport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if r.Method != http.MethodPost {
+		fail(w, errMethod)
+		return
+	}
+	q := r.URL.Query()
+	opt := usecase.CollectionOptions{
+		Collection: strings.TrimSpace(r.PathValue("name")),
+		MaxTier:    domain.StrategyUniqueness,
+	}
+	if opt.Collection == "" {
+		opt.Collection = strings.TrimSpace(q.Get("collection"))
+	}
+	if opt.Collection == "" {
+		fail(w, domain.Errorf(domain.KindInvalid, "missing collection"))
+		return
+	}
+	for _, t := range strings.Split(q.Get("tags"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			opt.Tags = append(opt.Tags, t)
+		}
+	}
+	maxTier, err := parseTier(q.Get("maxTier"), opt.MaxTier)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	opt.MaxTier = maxTier
+	if n, err := strconv.Atoi(q.Get("workers")); err == nil && n > 0 && n < usecase.BatchWorkers() {
+		opt.Workers = n
+	}
+	if n, err := strconv.Atoi(q.Get("after")); err == nil && n > 0 {
+		opt.Resume = &usecase.ImportReport{Line: n}
+		if n, err := strconv.Atoi(q.Get("imported")); err == nil && n > 0 {
+			opt.Resume.Imported = n
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	var rejected, duplicates int // issues already streamed
+	opt.Checkpoint = func(rep *usecase.ImportReport) error {
+		line := *rep
+		line.Rejected, line.Duplicates = rep.Rejected[rejected:], rep.Duplicates[duplicates:]
+		rejected, duplicates = len(rep.Rejected), len(rep.Duplicates)
+		if err := enc.Encode(&line); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	}
+	if _, err := h.UC.ImportCollection(r.Context(), http.MaxBytesReader(w, r.Body, maxBatchBody), opt); err != nil {
+		resp, _ := newErrorResp(err)
+		_ = enc.Encode(resp)
+	}
+}
@@ -0,0 +1,49 @@
+package httpadapter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"svw.info/sudoku/internal/infrastructure/storage"
+	"svw.info/sudoku/internal/solver"
+	"svw.info/sudoku/internal/usecase"
+	"svw.info/sudoku/internal/validator"
+)
+
+func TestCollectionImportStreamsNewIssues(t *testing.T) {
+	const line = "53..7....6..195....98....6.8...6...34..8.3..17...2...6.6....28....419..5....8..79"
+	s := solver.NewBacktrackingSolver()
+	mux := http.NewServeMux()
+	New(usecase.NewService(s, nil, validator.New(), nil, storage.NewFS(t.TempDir()))).Register(mux)
+
+	// one puzzle and 150 repeats of it: two checkpoints' worth of duplicates
+	body := strings.Repeat(line+"\n", 151)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/collections/c/import?after=0", strings.NewReader(body)))
+	var reps []usecase.ImportReport
+	dec := json.NewDecoder(rec.Body)
+	for dec.More() {
+		var rep usecase.ImportReport
+		if err := dec.Decode(&rep); err != nil {
+			t.Fatal(err)
+		}
+		reps = append(reps, rep)
+	}
+	if len(reps) != 2 || !reps[1].Done || reps[1].Imported != 1 {
+		t.Fatalf("reports = %+v", reps)
+	}
+	if len(reps[0].Duplicates) != 99 || len(reps[1].Duplicates) != 51 || reps[1].Duplicates[0].Line != 101 {
+		t.Fatalf("duplicates per line: %d, %d", len(reps[0].Duplicates), len(reps[1].Duplicates))
+	}
+
+	// a resume carries the count it is given
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/collections/c/import?after=140&imported=1", strings.NewReader(body)))
+	var rep usecase.ImportReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &rep); err != nil || !rep.Done || rep.Imported != 1 || len(rep.Duplicates) != 11 {
+		t.Fatalf("resumed report = %s", rec.Body)
+	}
+}
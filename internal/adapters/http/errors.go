@@ -0,0 +1,67 @@
+package httpadapter
+
+import (
+	"errors"
+	"net/http"
+
+	"svw.info/sudoku/internal/domain"
+)
+
+// ---- Errors ----
+
+// errorResp is the body of every error response. Code is the
+// domain.ErrorKind of the failure and stays stable across releases;
+// Message is for people.
+type errorResp struct {
+	Code    domain.ErrorKind `json:"code"`
+	Message string           `json:"message"`
+	Details map[string]any   `json:"details,omitempty"`
+	// Error repeats Message for clients of the unversioned API.
+	Error string `json:"error"`
+}
+
+// kindStatus is the HTTP status of each error kind.
+var kindStatus = map[domain.ErrorKind]int{
+	domain.KindInvalid:        http.StatusBadRequest,
+	domain.KindUnsolvable:     http.StatusUnprocessableEntity,
+	domain.KindNotUnique:      http.StatusUnprocessableEntity,
+	domain.KindNotFound:       http.StatusNotFound,
+	domain.KindConflict:       http.StatusConflict,
+	domain.KindTimeout:        http.StatusGatewayTimeout,
+	domain.KindCanceled:       499, // client closed the request, as nginx logs it
+	domain.KindNotImplemented: http.StatusNotImplemented,
+	domain.KindInternal:       http.StatusInternalServerError,
+}
+
+// errMethod answers a request to an unversioned endpoint with a method it
+// does not serve; v1 routes leave that to the mux.
+var errMethod = domain.Errorf(domain.KindInvalid, "method not allowed")
+
+func newErrorResp(err error) (errorResp, int) {
+	resp := errorResp{Code: domain.KindOf(err), Message: err.Error(), Error: err.Error()}
+	status := kindStatus[resp.Code]
+	var e *domain.Error
+	if errors.As(err, &e) {
+		resp.Details = e.Details
+	}
+	var tooBig *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooBig):
+		resp.Code, status = domain.KindInvalid, http.StatusRequestEntityTooLarge
+	case errors.Is(err, errMethod):
+		status = http.StatusMethodNotAllowed
+	}
+	return resp, status
+}
+
+// fail answers err with the status of its kind and an errorResp. This is
+// the one place that turns errors into HTTP responses.
+func fail(w http.ResponseWriter, err error) {
+	resp, status := newErrorResp(err)
+	writeJSON(w, status, resp)
+}
+
+// badJSON reports a request body that does not decode.
+func badJSON(err error) error {
+	return domain.Errorf(domain.KindInvalid, "invalid JSON: %v", err)
+}
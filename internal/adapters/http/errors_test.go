@@ -0,0 +1,33 @@
+package httpadapter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/usecase"
+)
+
+func TestWrongMethodIsJSON(t *testing.T) {
+	h := New(usecase.NewService(nil, nil, nil, nil, nil))
+	mux := http.NewServeMux()
+	h.Register(mux)
+	for _, req := range []*http.Request{
+		httptest.NewRequest("GET", "/api/solve", nil),
+		httptest.NewRequest("POST", "/api/export", nil),
+		httptest.NewRequest("DELETE", "/api/games/g1/replay", nil),
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		var resp errorResp
+		err := json.Unmarshal(rec.Body.Bytes(), &resp)
+		if rec.Code != http.StatusMethodNotAllowed || err != nil || resp.Code != domain.KindInvalid || resp.Error != "method not allowed" {
+			t.Errorf("%s %s: %d %s", req.Method, req.URL, rec.Code, rec.Body)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+			t.Errorf("%s %s: Content-Type %q", req.Method, req.URL, ct)
+		}
+	}
+}
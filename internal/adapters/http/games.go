@@ -0,0 +1,127 @@
+package httpadapter
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/replay"
+)
+
+// ---- Games ----
+
+type startGameReq struct {
+	PuzzleID string        `json:"puzzleId,omitempty"`
+	Board    *domain.Board `json:"board,omitempty"`
+}
+
+type gameResp struct {
+	Game    *domain.Game `json:"game,omitempty"`
+	CanUndo bool         `json:"canUndo"`
+	CanRedo bool         `json:"canRedo"`
+}
+
+func newGameResp(g *domain.Game) gameResp {
+	applied, redo := g.History()
+	return gameResp{Game: g, CanUndo: len(applied) > 0, CanRedo: len(redo) > 0}
+}
+
+func (h *Handler) handleGames(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if r.Method != http.MethodPost {
+		fail(w, errMethod)
+		return
+	}
+	var req startGameReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fail(w, badJSON(err))
+		return
+	}
+	g, err := h.UC.StartGame(r.Context(), req.PuzzleID, req.Board)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(newGameResp(g))
+}
+
+func (h *Handler) handleGame(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if r.Method != http.MethodGet {
+		fail(w, errMethod)
+		return
+	}
+	g, err := h.UC.Game(r.Context(), r.PathValue("id"))
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(newGameResp(g))
+}
+
+type moveReq struct {
+	Kind  domain.MoveKind `json:"kind"`
+	Row   int             `json:"row"`
+	Col   int             `json:"col"`
+	Value uint8           `json:"value,omitempty"`
+}
+
+func (h *Handler) handleGameMove(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if r.Method != http.MethodPost {
+		fail(w, errMethod)
+		return
+	}
+	var req moveReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fail(w, badJSON(err))
+		return
+	}
+	h.playMove(w, r, domain.Move{Kind: req.Kind, Row: req.Row, Col: req.Col, Value: req.Value})
+}
+
+func (h *Handler) handleGameUndo(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if r.Method != http.MethodPost {
+		fail(w, errMethod)
+		return
+	}
+	h.playMove(w, r, domain.Move{Kind: domain.MoveUndo})
+}
+
+func (h *Handler) handleGameRedo(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if r.Method != http.MethodPost {
+		fail(w, errMethod)
+		return
+	}
+	h.playMove(w, r, domain.Move{Kind: domain.MoveRedo})
+}
+
+func (h *Handler) playMove(w http.ResponseWriter, r *http.Request, m domain.Move) {
+	g, err := h.UC.PlayMove(r.Context(), r.PathValue("id"), m)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(newGameResp(g))
+}
+
+type replayResp struct {
+	Replay *replay.Replay `json:"replay,omitempty"`
+}
+
+func (h *Handler) handleGameReplay(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if r.Method != http.MethodGet {
+		fail(w, errMethod)
+		return
+	}
+	rp, err := h.UC.Replay(r.Context(), r.PathValue("id"))
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(replayResp{Replay: rp})
+}
@@ -0,0 +1,76 @@
+package httpadapter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/infrastructure/storage"
+	"svw.info/sudoku/internal/usecase"
+)
+
+func TestGameHandlers(t *testing.T) {
+	h := New(usecase.NewService(nil, nil, nil, nil, storage.NewFS(t.TempDir())))
+	mux := http.NewServeMux()
+	h.Register(mux)
+	do := func(method, path, body string, code int) gameResp {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		if rec.Code != code {
+			t.Fatalf("%s %s: %d %s, want %d", method, path, rec.Code, rec.Body, code)
+		}
+		var resp gameResp
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		return resp
+	}
+
+	board := `{"board":{"board":[[5,3,0,0,7,0,0,0,0]]}}`
+	g := do("POST", "/api/v1/games", board, http.StatusCreated).Game
+	if g == nil || g.ID == "" || g.PuzzleID == "" || !g.Start.Fixed[0][0] {
+		t.Fatalf("started game = %+v", g)
+	}
+	base := "/api/v1/games/" + g.ID
+
+	resp := do("POST", base+"/moves", `{"kind":"set","row":0,"col":2,"value":4}`, http.StatusOK)
+	if resp.Game.Board.Values[0][2] != 4 || !resp.CanUndo || resp.CanRedo {
+		t.Errorf("after set: %+v", resp)
+	}
+	if resp = do("POST", base+"/undo", "", http.StatusOK); resp.Game.Board.Values[0][2] != 0 || resp.CanUndo || !resp.CanRedo {
+		t.Errorf("after undo: %+v", resp)
+	}
+	if resp = do("GET", base, "", http.StatusOK); resp.Game.Board.Values[0][2] != 0 || !resp.CanRedo {
+		t.Errorf("reloaded after undo: %+v", resp)
+	}
+	if resp = do("POST", "/api/games/"+g.ID+"/redo", "", http.StatusOK); resp.Game.Board.Values[0][2] != 4 || !resp.CanUndo || resp.CanRedo {
+		t.Errorf("after redo: %+v", resp)
+	}
+	if resp = do("GET", "/api/games/"+g.ID, "", http.StatusOK); len(resp.Game.Moves) != 3 || resp.Game.Board.Values[0][2] != 4 {
+		t.Errorf("reloaded after redo: %+v", resp)
+	}
+
+	for _, tc := range []struct {
+		method, path, body string
+		code               int
+		kind               domain.ErrorKind
+	}{
+		{"POST", "/api/v1/games", `{}`, http.StatusBadRequest, domain.KindInvalid},
+		{"POST", "/api/v1/games", `{"puzzleId":"missing"}`, http.StatusNotFound, domain.KindNotFound},
+		{"GET", "/api/v1/games/missing", "", http.StatusNotFound, domain.KindNotFound},
+		{"POST", base + "/moves", `{"kind":"set","row":0,"col":0,"value":1}`, http.StatusBadRequest, domain.KindInvalid},
+		{"POST", base + "/redo", "", http.StatusConflict, domain.KindConflict},
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)))
+		var resp errorResp
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		if rec.Code != tc.code || resp.Code != tc.kind {
+			t.Errorf("%s %s %s: %d %s", tc.method, tc.path, tc.body, rec.Code, rec.Body)
+		}
+	}
+}
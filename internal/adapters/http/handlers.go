@@ -2,6 +2,7 @@ package httpadapter
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -13,22 +14,44 @@ import (
 
 type Handler struct {
 	UC *usecase.Service
+	// Daily serves the daily challenge; nil disables /api/daily.
+	Daily *usecase.Daily
 }
 
 func New(uc *usecase.Service) *Handler { return &Handler{UC: uc} }
 
 func (h *Handler) Register(mux *http.ServeMux) {
-	mux.HandleFunc("/api/generate", h.handleGenerate)
-	mux.HandleFunc("/api/solve", h.handleSolve)
-	mux.HandleFunc("/api/validate", h.handleValidate)
-	mux.HandleFunc("/api/hint", h.handleHint)
-	mux.HandleFunc("/api/save", h.handleSave)
-	mux.HandleFunc("/api/load", h.handleLoad)
-	mux.HandleFunc("/api/list", h.handleList)
+	h.registerV1(mux)
+
+	// The unversioned API, kept for existing clients.
+	mux.HandleFunc("/api/generate", deprecated(h.handleGenerate))
+	mux.HandleFunc("/api/solve", deprecated(h.handleSolve))
+	mux.HandleFunc("/api/validate", deprecated(h.handleValidate))
+	mux.HandleFunc("/api/marks/check", deprecated(h.handleMarksCheck))
+	mux.HandleFunc("/api/hint", deprecated(h.handleHint))
+	mux.HandleFunc("/api/batch", deprecated(h.handleBatch))
+	mux.HandleFunc("/api/save", deprecated(h.handleSave))
+	mux.HandleFunc("/api/load", deprecated(h.handleLoad))
+	mux.HandleFunc("/api/list", deprecated(h.handleList))
+	mux.HandleFunc("/api/import", deprecated(h.handleImport))
+	mux.HandleFunc("/api/import/image", deprecated(h.handleImageImport))
+	mux.HandleFunc("/api/export", deprecated(h.handleExport))
+	mux.HandleFunc("/api/print", deprecated(h.handlePrint))
+	mux.HandleFunc("/api/render", deprecated(h.handleRender))
+	mux.HandleFunc("/api/render/{file}", deprecated(h.handleRenderStored))
+	mux.HandleFunc("/api/collections/import", deprecated(h.handleCollectionImport))
+	mux.HandleFunc("/api/daily", deprecated(h.handleDaily))
+	mux.HandleFunc("/api/daily/archive", deprecated(h.handleDailyArchive))
+	mux.HandleFunc("/api/games", deprecated(h.handleGames))
+	mux.HandleFunc("/api/games/{id}", deprecated(h.handleGame))
+	mux.HandleFunc("/api/games/{id}/moves", deprecated(h.handleGameMove))
+	mux.HandleFunc("/api/games/{id}/undo", deprecated(h.handleGameUndo))
+	mux.HandleFunc("/api/games/{id}/redo", deprecated(h.handleGameRedo))
+	mux.HandleFunc("/api/games/{id}/replay", deprecated(h.handleGameReplay))
 }
 
 func notImplemented(w http.ResponseWriter, r *http.Request) {
-	http.Error(w, "not implemented", http.StatusNotImplemented)
+	fail(w, domain.Errorf(domain.KindNotImplemented, "not implemented"))
 }
 
 // ---- Generate ----
@@ -44,7 +67,6 @@ type generateResp struct {
 	Difficulty string       `json:"difficulty,omitempty"`
 	DurationMs int64        `json:"durationMs,omitempty"`
 	Nodes      int          `json:"nodes,omitempty"`
-	Error      string       `json:"error,omitempty"`
 }
 
 func parseDifficulty(s string) domain.Difficulty {
@@ -63,13 +85,12 @@ func parseDifficulty(s string) domain.Difficulty {
 func (h *Handler) handleGenerate(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	if r.Method != http.MethodPost {
-		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		fail(w, errMethod)
 		return
 	}
 	var req generateReq
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err.Error() != "EOF" {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(generateResp{Error: "invalid JSON: " + err.Error()})
+		fail(w, badJSON(err))
 		return
 	}
 	seed := req.Seed
@@ -79,8 +100,7 @@ func (h *Handler) handleGenerate(w http.ResponseWriter, r *http.Request) {
 	diff := parseDifficulty(req.Difficulty)
 	p, st, err := h.UC.Generate(r.Context(), seed, diff)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(generateResp{Error: err.Error()})
+		fail(w, err)
 		return
 	}
 	_ = json.NewEncoder(w).Encode(generateResp{
@@ -96,33 +116,96 @@ func (h *Handler) handleGenerate(w http.ResponseWriter, r *http.Request) {
 
 type validateReq struct {
 	Board [9][9]uint8 `json:"board"`
+	Fixed [9][9]bool  `json:"fixed,omitempty"`
+	// Mode "solution" also compares entries with the unique solution of the fixed givens;
+	// "deep" adds dead-end detection (cells or digits with nowhere left to go).
+	Mode string `json:"mode,omitempty"`
 }
 type validateResp struct {
 	OK        bool               `json:"ok"`
 	Conflicts []domain.CellCoord `json:"conflicts,omitempty"`
-	Error     string             `json:"error,omitempty"`
+	Wrong     []domain.CellCoord `json:"wrong,omitempty"`
+	Solvable  *bool              `json:"solvable,omitempty"`
+	// Contradictions is set in deep mode.
+	Contradictions []domain.Contradiction `json:"contradictions,omitempty"`
 }
 
 func (h *Handler) handleValidate(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	if r.Method != http.MethodPost {
-		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		fail(w, errMethod)
 		return
 	}
 	var req validateReq
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(validateResp{Error: "invalid JSON: " + err.Error()})
+		fail(w, badJSON(err))
+		return
+	}
+	b := &domain.Board{Values: req.Board, Fixed: req.Fixed}
+	if strings.EqualFold(req.Mode, "solution") {
+		chk, err := h.UC.CheckSolution(r.Context(), b)
+		if err != nil {
+			fail(w, err)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(validateResp{
+			OK:        len(chk.Conflicts) == 0 && len(chk.Wrong) == 0,
+			Conflicts: chk.Conflicts,
+			Wrong:     chk.Wrong,
+			Solvable:  &chk.Solvable,
+		})
 		return
 	}
-	b := &domain.Board{Values: req.Board}
 	ok, conflicts, err := h.UC.Validate(r.Context(), b)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(validateResp{Error: err.Error()})
+		fail(w, err)
 		return
 	}
-	_ = json.NewEncoder(w).Encode(validateResp{OK: ok, Conflicts: conflicts})
+	var contra []domain.Contradiction
+	if strings.EqualFold(req.Mode, "deep") {
+		if contra, err = h.UC.Contradictions(r.Context(), b); err != nil {
+			fail(w, err)
+			return
+		}
+	}
+	_ = json.NewEncoder(w).Encode(validateResp{OK: ok && len(contra) == 0, Conflicts: conflicts, Contradictions: contra})
+}
+
+// ---- Pencil marks ----
+
+type marksReq struct {
+	Board [9][9]uint8  `json:"board"`
+	Fixed [9][9]bool   `json:"fixed,omitempty"`
+	Marks domain.Marks `json:"marks"`
+}
+type marksResp struct {
+	OK         bool               `json:"ok"`
+	Missing    []domain.Candidate `json:"missing,omitempty"`
+	Impossible []domain.Candidate `json:"impossible,omitempty"`
+}
+
+func (h *Handler) handleMarksCheck(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if r.Method != http.MethodPost {
+		fail(w, errMethod)
+		return
+	}
+	var req marksReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fail(w, badJSON(err))
+		return
+	}
+	b := &domain.Board{Values: req.Board, Fixed: req.Fixed}
+	rep, err := h.UC.CheckMarks(r.Context(), b, &req.Marks)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(marksResp{
+		OK:         len(rep.Missing) == 0 && len(rep.Impossible) == 0,
+		Missing:    rep.Missing,
+		Impossible: rep.Impossible,
+	})
 }
 
 // ---- Solve ----
@@ -134,26 +217,23 @@ type solveResp struct {
 	Board      [9][9]uint8 `json:"board,omitempty"`
 	DurationMs int64       `json:"durationMs,omitempty"`
 	Nodes      int         `json:"nodes,omitempty"`
-	Error      string      `json:"error,omitempty"`
 }
 
 func (h *Handler) handleSolve(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	if r.Method != http.MethodPost {
-		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		fail(w, errMethod)
 		return
 	}
 	var req solveReq
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(solveResp{Error: "invalid JSON: " + err.Error()})
+		fail(w, badJSON(err))
 		return
 	}
 	in := &domain.Board{Values: req.Board}
 	out, st, err := h.UC.Solve(r.Context(), in)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(solveResp{Error: err.Error(), DurationMs: st.Duration.Milliseconds(), Nodes: st.Nodes})
+		fail(w, err)
 		return
 	}
 	_ = json.NewEncoder(w).Encode(solveResp{Board: out.Values, DurationMs: st.Duration.Milliseconds(), Nodes: st.Nodes})
@@ -164,108 +244,154 @@ func (h *Handler) handleSolve(w http.ResponseWriter, r *http.Request) {
 type hintReq struct {
 	Board   [9][9]uint8 `json:"board"`
 	MaxTier string      `json:"maxTier,omitempty"`
+	// Repeated requests for the same step escalate disclosure per session
+	// (or per game, which also records hint usage). Level forces 1..4.
+	Session string           `json:"session,omitempty"`
+	GameID  string           `json:"gameId,omitempty"`
+	Level   domain.HintLevel `json:"level,omitempty"`
+	// Candidates, when set, are the player's pencil marks to hint from.
+	Candidates *domain.Marks `json:"candidates,omitempty"`
+	// Fallback asks for a forcing chain or revealed digit when no strategy applies.
+	Fallback bool `json:"fallback,omitempty"`
 }
 type hintResp struct {
-	Found bool         `json:"found"`
-	Hint  domain.Hint  `json:"hint,omitempty"`
-	Error string       `json:"error,omitempty"`
+	Found     bool        `json:"found"`
+	Hint      domain.Hint `json:"hint,omitempty"`
+	HintsUsed int         `json:"hintsUsed,omitempty"`
 }
 
-func parseTier(s string) domain.StrategyTier {
-	switch strings.ToLower(strings.TrimSpace(s)) {
-	case "singles":
-		return domain.StrategySingles
-	case "pairs":
-		return domain.StrategyPairs
-	case "advanced":
-		return domain.StrategyAdvanced
-	case "xwing":
-		return domain.StrategyXWing
-	default:
-		return domain.StrategySingles
+// parseTier reads a maxTier value, falling back to def when it is empty.
+func parseTier(s string, def domain.StrategyTier) (domain.StrategyTier, error) {
+	if strings.TrimSpace(s) == "" {
+		return def, nil
 	}
+	if t, ok := domain.ParseStrategyTier(s); ok {
+		return t, nil
+	}
+	return 0, domain.Errorf(domain.KindInvalid, "unknown maxTier %q", s)
 }
 
 func (h *Handler) handleHint(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	if r.Method != http.MethodPost {
-		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		fail(w, errMethod)
 		return
 	}
 	var req hintReq
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(hintResp{Error: "invalid JSON: " + err.Error()})
+		fail(w, badJSON(err))
+		return
+	}
+	max, err := parseTier(req.MaxTier, domain.StrategySingles)
+	if err != nil {
+		fail(w, err)
 		return
 	}
-	max := parseTier(req.MaxTier)
 	b := &domain.Board{Values: req.Board}
-	hh, ok, err := h.UC.Hint(r.Context(), b, max)
+	hh, ok, used, err := h.UC.GradedHint(r.Context(), req.Session, req.GameID, b, req.Candidates, max, req.Level, req.Fallback)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(hintResp{Error: err.Error()})
+		fail(w, err)
 		return
 	}
-	_ = json.NewEncoder(w).Encode(hintResp{Found: ok, Hint: hh})
+	_ = json.NewEncoder(w).Encode(hintResp{Found: ok, Hint: hh, HintsUsed: used})
 }
 
 // ---- Save / Load / List ----
 
+// saveReq is a puzzle whose difficulty may be left out: Easy is the zero
+// Difficulty, so only the key's presence tells it from "keep the stored one".
+type saveReq struct {
+	domain.Puzzle
+	Difficulty *domain.Difficulty `json:"difficulty"`
+}
+
 type saveResp struct {
-	ID    string `json:"id,omitempty"`
-	Error string `json:"error,omitempty"`
+	ID string `json:"id,omitempty"`
 }
 
+// handleSave is the page's save and autosave. Saving over a stored puzzle
+// replaces its board, name, notes and play state; what the page does not
+// send (creation time, difficulty, seed, collection, tags and favorite)
+// keeps its stored value when the body leaves it empty or, for the
+// difficulty, out. PUT and PATCH on
+// /api/v1/puzzles/{id} replace or change those.
 func (h *Handler) handleSave(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	if r.Method != http.MethodPost {
-		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		fail(w, errMethod)
 		return
 	}
-	var p domain.Puzzle
-	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(saveResp{Error: "invalid JSON: " + err.Error()})
+	var req saveReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fail(w, badJSON(err))
 		return
 	}
+	p := req.Puzzle
+	if req.Difficulty != nil {
+		p.Difficulty = *req.Difficulty
+	}
 	if p.ID == "" {
 		p.ID = strconv.FormatInt(time.Now().UnixNano(), 10)
+	} else if old, err := h.UC.Load(r.Context(), p.ID); err == nil {
+		keepStored(&p, old)
+		if req.Difficulty == nil {
+			p.Difficulty = old.Difficulty
+		}
+	} else if !errors.Is(err, domain.ErrNotFound) {
+		fail(w, err)
+		return
 	}
 	if p.CreatedAt == 0 {
 		p.CreatedAt = time.Now().UnixNano()
 	}
 	if err := h.UC.Save(r.Context(), &p); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(saveResp{Error: err.Error()})
+		fail(w, err)
 		return
 	}
 	_ = json.NewEncoder(w).Encode(saveResp{ID: p.ID})
 }
 
+func keepStored(p, old *domain.Puzzle) {
+	if p.CreatedAt == 0 {
+		p.CreatedAt = old.CreatedAt
+	}
+	if p.Seed == 0 {
+		p.Seed = old.Seed
+	}
+	if p.Collection == "" {
+		p.Collection = old.Collection
+	}
+	if p.Tags == nil {
+		p.Tags = old.Tags
+	}
+	p.Favorite = p.Favorite || old.Favorite
+}
+
 type loadReq struct {
 	ID string `json:"id"`
 }
 type loadResp struct {
 	Puzzle *domain.Puzzle `json:"puzzle,omitempty"`
-	Error  string         `json:"error,omitempty"`
 }
 
 func (h *Handler) handleLoad(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	if r.Method != http.MethodPost {
-		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		fail(w, errMethod)
 		return
 	}
 	var req loadReq
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ID == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(loadResp{Error: "invalid JSON or missing id"})
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fail(w, badJSON(err))
+		return
+	}
+	if req.ID == "" {
+		fail(w, domain.Errorf(domain.KindInvalid, "missing id"))
 		return
 	}
 	p, err := h.UC.Load(r.Context(), req.ID)
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
-		_ = json.NewEncoder(w).Encode(loadResp{Error: err.Error()})
+		fail(w, err)
 		return
 	}
 	_ = json.NewEncoder(w).Encode(loadResp{Puzzle: p})
@@ -273,20 +399,86 @@ func (h *Handler) handleLoad(w http.ResponseWriter, r *http.Request) {
 
 type listResp struct {
 	Puzzles []domain.PuzzleMeta `json:"puzzles"`
-	Error   string              `json:"error,omitempty"`
 }
 
 func (h *Handler) handleList(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	if r.Method != http.MethodGet {
-		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		fail(w, errMethod)
 		return
 	}
 	ps, err := h.UC.List(r.Context())
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(listResp{Error: err.Error()})
+		fail(w, err)
 		return
 	}
 	_ = json.NewEncoder(w).Encode(listResp{Puzzles: ps})
-}
\ No newline at end of file
+}
+
+// ---- Daily ----
+
+type dailyResp struct {
+	Date       string         `json:"date,omitempty"`
+	Difficulty string         `json:"difficulty,omitempty"`
+	Puzzle     *domain.Puzzle `json:"puzzle,omitempty"`
+}
+
+func (h *Handler) handleDaily(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if r.Method != http.MethodGet {
+		fail(w, errMethod)
+		return
+	}
+	if h.Daily == nil {
+		notImplemented(w, r)
+		return
+	}
+	q := r.URL.Query()
+	diff := parseDifficulty(q.Get("difficulty"))
+	p, date, err := h.Daily.Get(r.Context(), q.Get("date"), diff)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(dailyResp{Date: date, Difficulty: diff.String(), Puzzle: p})
+}
+
+type dailyArchiveResp struct {
+	Dailies []dailyEntry `json:"dailies"`
+}
+
+// dailyEntry is a usecase.DailyEntry with the difficulty named as in
+// dailyResp and the difficulty query parameter.
+type dailyEntry struct {
+	Date       string `json:"date"`
+	Difficulty string `json:"difficulty"`
+	ID         string `json:"id"`
+	CreatedAt  int64  `json:"createdAt"`
+}
+
+func (h *Handler) handleDailyArchive(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if r.Method != http.MethodGet {
+		fail(w, errMethod)
+		return
+	}
+	if h.Daily == nil {
+		notImplemented(w, r)
+		return
+	}
+	all, err := h.Daily.Archive(r.Context())
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	ds := r.URL.Query().Get("difficulty")
+	want := parseDifficulty(ds)
+	out := make([]dailyEntry, 0, len(all))
+	for _, e := range all {
+		if ds != "" && e.Difficulty != want {
+			continue
+		}
+		out = append(out, dailyEntry{Date: e.Date, Difficulty: e.Difficulty.String(), ID: e.ID, CreatedAt: e.CreatedAt})
+	}
+	_ = json.NewEncoder(w).Encode(dailyArchiveResp{Dailies: out})
+}
@@ -0,0 +1,88 @@
+package httpadapter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/generator"
+	"svw.info/sudoku/internal/infrastructure/storage"
+	"svw.info/sudoku/internal/solver"
+	"svw.info/sudoku/internal/usecase"
+)
+
+func TestSaveKeepsOrSetsDifficulty(t *testing.T) {
+	uc := usecase.NewService(nil, nil, nil, nil, storage.NewFS(t.TempDir()))
+	mux := http.NewServeMux()
+	New(uc).Register(mux)
+	for _, c := range []struct {
+		body string
+		want domain.Difficulty
+	}{
+		{`{"id":"p1","difficulty":2}`, domain.Hard},
+		{`{"id":"p1","name":"autosave"}`, domain.Hard}, // left out: keep it
+		{`{"id":"p1","difficulty":0}`, domain.Easy},    // explicitly easy
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("POST", "/api/save", strings.NewReader(c.body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: %d %s", c.body, rec.Code, rec.Body)
+		}
+		p, err := uc.Load(context.Background(), "p1")
+		if err != nil || p.Difficulty != c.want {
+			t.Fatalf("%s: stored %v (%v), want %v", c.body, p, err, c.want)
+		}
+	}
+}
+
+func TestDailyArchiveNamesDifficulty(t *testing.T) {
+	st := storage.NewFS(t.TempDir())
+	h := New(usecase.NewService(nil, nil, nil, nil, st))
+	h.Daily = usecase.NewDaily(generator.NewUniqueGenerator(solver.NewDLXSolver()), st)
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	get := func(url string, v any) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", url, nil))
+		if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), v) != nil {
+			t.Fatalf("GET %s: %d %s", url, rec.Code, rec.Body)
+		}
+	}
+	var daily dailyResp
+	get("/api/v1/daily?difficulty=hard", &daily)
+	var archive dailyArchiveResp
+	get("/api/v1/daily/archive?difficulty=hard", &archive)
+	if len(archive.Dailies) != 1 || archive.Dailies[0].Difficulty != daily.Difficulty || daily.Difficulty != "hard" {
+		t.Fatalf("daily %q, archive %+v", daily.Difficulty, archive.Dailies)
+	}
+}
+
+func TestUnknownMaxTierIsRejected(t *testing.T) {
+	h := New(usecase.NewService(nil, nil, nil, nil, nil))
+	mux := http.NewServeMux()
+	h.Register(mux)
+	for _, req := range []*http.Request{
+		httptest.NewRequest("POST", "/api/v1/hint", strings.NewReader(`{"maxTier":"swordfish"}`)),
+		httptest.NewRequest("POST", "/api/v1/batch?maxTier=swordfish", strings.NewReader("")),
+		httptest.NewRequest("POST", "/api/v1/collections/c/import?maxTier=swordfish", strings.NewReader("")),
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		var resp errorResp
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		if rec.Code != http.StatusBadRequest || resp.Code != domain.KindInvalid || !strings.Contains(resp.Error, "swordfish") {
+			t.Errorf("%s: %d %s", req.URL, rec.Code, rec.Body)
+		}
+	}
+	for tier := domain.StrategySingles; tier <= domain.StrategyLastResort; tier++ {
+		if got, ok := domain.ParseStrategyTier(" " + strings.ToUpper(tier.String()) + " "); !ok || got != tier {
+			t.Errorf("ParseStrategyTier(%v) = %v, %v", tier, got, ok)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package httpadapter
+
+import (
+	"net/http"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"svw.info/sudoku/internal/codec"
+	"svw.info/sudoku/internal/domain"
+)
+
+// ---- OpenAPI ----
+
+// handleOpenAPI serves the OpenAPI 3.1 document of the v1 API, built from
+// the endpoints table and the Go types of the bodies it names.
+func (h *Handler) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	writeJSON(w, http.StatusOK, openAPI())
+}
+
+var openAPI = sync.OnceValue(func() map[string]any { return buildSpec(endpoints) })
+
+// enumSchemas describe the named scalars whose Go type alone says too
+// little.
+var enumSchemas = map[reflect.Type]map[string]any{
+	reflect.TypeFor[domain.Difficulty](): {
+		"type": "integer", "enum": []int{0, 1, 2, 3},
+		"description": "0 easy, 1 medium, 2 hard, 3 expert",
+	},
+	reflect.TypeFor[domain.StrategyTier](): {
+		"type": "integer", "enum": []int{0, 1, 2, 3, 4, 5, 6, 7},
+		"description": "0 singles, 1 pairs, 2 advanced, 3 xwing, 4 chains, 5 finned, 6 uniqueness, 7 lastresort",
+	},
+	reflect.TypeFor[domain.HintLevel](): {
+		"type": "integer", "enum": []int{0, 1, 2, 3, 4},
+		"description": "1 names the technique, 2 the house, 3 the cells, 4 gives the answer; 0 discloses everything",
+	},
+	reflect.TypeFor[domain.Digits](): {
+		"type": "integer", "minimum": 0, "maximum": 1022,
+		"description": "set of digits: bit d is set when digit d is",
+	},
+	reflect.TypeFor[domain.LinkKind]():          {"type": "string", "enum": []domain.LinkKind{domain.LinkStrong, domain.LinkWeak}},
+	reflect.TypeFor[domain.HouseKind]():         {"type": "string", "enum": []domain.HouseKind{domain.HouseRow, domain.HouseCol, domain.HouseBox}},
+	reflect.TypeFor[domain.ContradictionKind](): {"type": "string", "enum": []domain.ContradictionKind{domain.NoCandidates, domain.NoPlace, domain.SameSingle}},
+	reflect.TypeFor[domain.MoveKind]():          {"type": "string", "enum": []domain.MoveKind{domain.MoveSet, domain.MoveClear, domain.MoveNote, domain.MoveUndo, domain.MoveRedo}},
+	reflect.TypeFor[domain.ErrorKind](): {"type": "string", "enum": []domain.ErrorKind{
+		domain.KindInvalid, domain.KindUnsolvable, domain.KindNotUnique, domain.KindNotFound, domain.KindConflict,
+		domain.KindTimeout, domain.KindCanceled, domain.KindNotImplemented, domain.KindInternal,
+	}},
+	reflect.TypeFor[codec.Format](): {"type": "string", "enum": codec.Formats},
+}
+
+func buildSpec(eps []endpoint) map[string]any {
+	s := &schemas{components: map[string]any{}, names: map[reflect.Type]string{}}
+	paths := map[string]map[string]any{}
+	for _, e := range eps {
+		if paths[e.path] == nil {
+			paths[e.path] = map[string]any{}
+		}
+		paths[e.path][strings.ToLower(e.method)] = s.operation(e)
+	}
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":       "Sudoku API",
+			"version":     "1",
+			"description": "Generate, solve, check and store puzzles and play games. The unversioned /api/... routes are deprecated aliases.",
+		},
+		"paths":      paths,
+		"components": map[string]any{"schemas": s.components},
+	}
+}
+
+// operationID names an endpoint after its handler, without the handle
+// prefix: handleGameMove is gameMove.
+func operationID(e endpoint) string {
+	name := runtime.FuncForPC(reflect.ValueOf(e.handle).Pointer()).Name()
+	name = strings.TrimPrefix(name[strings.LastIndex(name, ".")+1:], "handle")
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+func (s *schemas) operation(e endpoint) map[string]any {
+	op := map[string]any{"operationId": operationID(e), "summary": e.summary}
+
+	var params []any
+	for _, seg := range strings.Split(e.path, "/") {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			params = append(params, map[string]any{
+				"name": strings.Trim(seg, "{}"), "in": "path", "required": true,
+				"schema": map[string]any{"type": "string"},
+			})
+		}
+	}
+	for _, p := range e.params {
+		params = append(params, map[string]any{
+			"name": p.name, "in": "query", "description": p.desc,
+			"schema": map[string]any{"type": p.typ},
+		})
+	}
+	if params != nil {
+		op["parameters"] = params
+	}
+
+	if e.body != nil || e.bodyTypes != nil {
+		content := map[string]any{}
+		if e.body != nil {
+			content["application/json"] = map[string]any{"schema": s.of(reflect.TypeOf(e.body))}
+		}
+		for _, m := range e.bodyTypes {
+			content[m] = map[string]any{"schema": opaque(m)}
+		}
+		op["requestBody"] = map[string]any{"content": content}
+	}
+
+	status := e.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	ok := map[string]any{"description": http.StatusText(status)}
+	types := e.respTypes
+	if types == nil && e.resp != nil {
+		types = []string{"application/json"}
+	}
+	if types != nil {
+		content := map[string]any{}
+		for _, m := range types {
+			schema := opaque(m)
+			if e.resp != nil {
+				schema = s.of(reflect.TypeOf(e.resp))
+			}
+			content[m] = map[string]any{"schema": schema}
+		}
+		ok["content"] = content
+		if e.resp != nil && e.respTypes != nil {
+			ok["description"] = "A stream with one JSON value per line."
+		}
+	}
+	op["responses"] = map[string]any{
+		strconv.Itoa(status): ok,
+		"default": map[string]any{
+			"description": "Error; code is one of the domain error kinds.",
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": s.of(reflect.TypeFor[errorResp]())},
+			},
+		},
+	}
+	return op
+}
+
+// opaque is the schema of a body that is not JSON.
+func opaque(media string) map[string]any {
+	switch {
+	case strings.HasPrefix(media, "text/"):
+		return map[string]any{"type": "string"}
+	case media == "multipart/form-data":
+		return map[string]any{"type": "object"}
+	default:
+		return map[string]any{"type": "string", "contentEncoding": "binary"}
+	}
+}
+
+func ref(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+// schemas derives JSON schemas from Go types the way encoding/json
+// marshals them. Named structs and enumSchemas become components.
+type schemas struct {
+	components map[string]any
+	names      map[reflect.Type]string
+}
+
+func (s *schemas) of(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if enum, ok := enumSchemas[t]; ok {
+		return ref(s.component(t, func() map[string]any { return enum }))
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Array:
+		return map[string]any{"type": "array", "items": s.of(t.Elem()), "minItems": t.Len(), "maxItems": t.Len()}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]any{"type": "array", "items": s.of(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": s.of(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return ref(s.component(t, func() map[string]any { return s.object(t) }))
+	default:
+		return map[string]any{}
+	}
+}
+
+// component registers the schema of the named type t once and returns its
+// name: the type name, capitalised, qualified by its package on a clash.
+func (s *schemas) component(t reflect.Type, build func() map[string]any) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+	name := strings.ToUpper(t.Name()[:1]) + t.Name()[1:]
+	if _, taken := s.components[name]; taken {
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	s.names[t] = name
+	s.components[name] = nil // reserved while recursive types are built
+	s.components[name] = build()
+	return name
+}
+
+// omitted reports whether a field of type t with the json tag options opts
+// can be left out: omitempty never drops structs or non-empty arrays.
+func omitted(t reflect.Type, opts string) bool {
+	if !strings.Contains(","+opts+",", ",omitempty,") {
+		return false
+	}
+	return t.Kind() != reflect.Struct && (t.Kind() != reflect.Array || t.Len() == 0)
+}
+
+// object lists the fields of struct t as encoding/json does: exported,
+// renamed by their json tag, embedded structs inlined. Fields that are
+// never omitted are required, though nil slices, maps and pointers among
+// them come out as null.
+func (s *schemas) object(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	var required []string
+	var fields func(t reflect.Type)
+	fields = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			if f.Anonymous && name == "" {
+				ft := f.Type
+				if ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				if ft.Kind() == reflect.Struct {
+					fields(ft)
+					continue
+				}
+			}
+			if !f.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			schema := s.of(f.Type)
+			if !omitted(f.Type, opts) {
+				required = append(required, name)
+				switch f.Type.Kind() {
+				case reflect.Pointer, reflect.Slice, reflect.Map:
+					schema = map[string]any{"anyOf": []any{schema, map[string]any{"type": "null"}}}
+				}
+			}
+			props[name] = schema
+		}
+	}
+	fields(t)
+	schema := map[string]any{"type": "object", "properties": props}
+	if required != nil {
+		schema["required"] = required
+	}
+	return schema
+}
@@ -0,0 +1,227 @@
+package httpadapter
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/constant"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestOpenAPIMatchesHandlers reads the handlers' source to check that each
+// endpoint documents the type its handler decodes, the type it encodes and
+// the parameters it reads, so the spec cannot drift from the code.
+func TestOpenAPIMatchesHandlers(t *testing.T) {
+	pkg, info, decls := checkPackage(t)
+	for _, e := range endpoints {
+		name := runtime.FuncForPC(reflect.ValueOf(e.handle).Pointer()).Name()
+		name = name[strings.LastIndex(name, ".")+1:]
+		obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(pkg.Scope().Lookup("Handler").Type()), true, pkg, name)
+		if obj == nil || decls[obj] == nil {
+			t.Fatalf("%s %s: handler %s not found", e.method, e.path, name)
+		}
+		u := &usage{info: info, decls: decls, seen: map[types.Object]bool{}}
+		u.walk(obj)
+
+		route := e.method + " " + e.path
+		if got, want := u.decoded, typeNames(e.body); !slices.Equal(got, want) {
+			t.Errorf("%s: %s decodes %v, spec says %v", route, name, got, want)
+		}
+		// Every handler may fail with an errorResp, the default response.
+		u.encoded = slices.DeleteFunc(u.encoded, func(s string) bool { return s == typeNames(errorResp{})[0] })
+		if got, want := u.encoded, typeNames(e.resp); !slices.Equal(got, want) {
+			t.Errorf("%s: %s encodes %v, spec says %v", route, name, got, want)
+		}
+		for _, v := range u.pathValues {
+			if !strings.Contains(e.path, "{"+v+"}") {
+				t.Errorf("%s: %s reads the undocumented path value %s", route, name, v)
+			}
+		}
+		for _, seg := range strings.Split(e.path, "/") {
+			if v, ok := strings.CutPrefix(seg, "{"); ok && !slices.Contains(u.pathValues, strings.TrimSuffix(v, "}")) {
+				t.Errorf("%s: %s never reads the path value %s", route, name, seg)
+			}
+		}
+		for _, p := range e.params {
+			if !slices.Contains(u.queries, p.name) {
+				t.Errorf("%s: %s never reads the documented ?%s=", route, name, p.name)
+			}
+		}
+	}
+}
+
+func TestOpenAPIRefs(t *testing.T) {
+	raw, err := json.Marshal(openAPI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spec struct {
+		Paths      map[string]map[string]struct{ OperationID string }
+		Components struct{ Schemas map[string]json.RawMessage }
+	}
+	if err := json.Unmarshal(raw, &spec); err != nil {
+		t.Fatal(err)
+	}
+	ids := map[string]bool{}
+	for _, item := range spec.Paths {
+		for _, op := range item {
+			if ids[op.OperationID] {
+				t.Errorf("operationId %s is not unique", op.OperationID)
+			}
+			ids[op.OperationID] = true
+		}
+	}
+	const prefix = `"#/components/schemas/`
+	for s := string(raw); strings.Contains(s, prefix); {
+		_, s, _ = strings.Cut(s, prefix)
+		name := s[:strings.IndexByte(s, '"')]
+		if spec.Components.Schemas[name] == nil {
+			t.Errorf("$ref to missing schema %s", name)
+		}
+	}
+}
+
+func checkPackage(t *testing.T) (*types.Package, *types.Info, map[types.Object]*ast.FuncDecl) {
+	t.Helper()
+	names, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range names {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, f)
+	}
+	info := &types.Info{
+		Types: map[ast.Expr]types.TypeAndValue{},
+		Defs:  map[*ast.Ident]types.Object{},
+		Uses:  map[*ast.Ident]types.Object{},
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check("svw.info/sudoku/internal/adapters/http", fset, files, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decls := map[types.Object]*ast.FuncDecl{}
+	for _, f := range files {
+		for _, d := range f.Decls {
+			if fd, ok := d.(*ast.FuncDecl); ok {
+				decls[info.Defs[fd.Name]] = fd
+			}
+		}
+	}
+	return pkg, info, decls
+}
+
+// usage collects what a handler does with JSON and the request, following
+// calls into the rest of the package.
+type usage struct {
+	info  *types.Info
+	decls map[types.Object]*ast.FuncDecl
+	seen  map[types.Object]bool
+
+	decoded, encoded    []string // named types, sorted
+	queries, pathValues []string
+}
+
+func (u *usage) walk(fn types.Object) {
+	if u.seen[fn] {
+		return
+	}
+	u.seen[fn] = true
+	ast.Inspect(u.decls[fn].Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		var id *ast.Ident
+		switch f := call.Fun.(type) {
+		case *ast.Ident:
+			id = f
+		case *ast.SelectorExpr:
+			id = f.Sel
+		default:
+			return true
+		}
+		callee, ok := u.info.Uses[id].(*types.Func)
+		if !ok {
+			return true
+		}
+		switch callee.FullName() {
+		case "(*encoding/json.Decoder).Decode":
+			u.decoded = u.add(u.decoded, call.Args[0])
+		case "encoding/json.Unmarshal":
+			u.decoded = u.add(u.decoded, call.Args[1])
+		case "(*encoding/json.Encoder).Encode":
+			u.encoded = u.add(u.encoded, call.Args[0])
+		case "(net/url.Values).Get":
+			u.queries = u.literal(u.queries, call.Args[0])
+		case "(*net/http.Request).PathValue":
+			u.pathValues = u.literal(u.pathValues, call.Args[0])
+		}
+		if u.decls[callee] != nil && callee.Name() == "writeJSON" {
+			u.encoded = u.add(u.encoded, call.Args[2])
+		}
+		if u.decls[callee] != nil {
+			u.walk(callee)
+		}
+		return true
+	})
+}
+
+// add records the named struct type of e, seen through pointers.
+func (u *usage) add(list []string, e ast.Expr) []string {
+	t := u.info.TypeOf(e)
+	for {
+		p, ok := t.(*types.Pointer)
+		if !ok {
+			break
+		}
+		t = p.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return list
+	}
+	if _, ok := named.Underlying().(*types.Struct); !ok {
+		return list
+	}
+	return insert(list, named.Obj().Pkg().Path()+"."+named.Obj().Name())
+}
+
+func (u *usage) literal(list []string, e ast.Expr) []string {
+	if v := u.info.Types[e].Value; v != nil && v.Kind() == constant.String {
+		return insert(list, constant.StringVal(v))
+	}
+	return list
+}
+
+func insert(list []string, s string) []string {
+	if i, found := slices.BinarySearch(list, s); !found {
+		list = slices.Insert(list, i, s)
+	}
+	return list
+}
+
+func typeNames(v any) []string {
+	if v == nil {
+		return nil
+	}
+	t := reflect.TypeOf(v)
+	return []string{t.PkgPath() + "." + t.Name()}
+}
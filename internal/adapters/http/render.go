@@ -0,0 +1,188 @@
+package httpadapter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/render"
+)
+
+// ---- Render ----
+
+// handleRenderStored draws a saved puzzle: the path is {id}.svg or
+// {id}.png. ?size= and ?theme= pick the look, ?solution=1 fills in the
+// solution, ?marks=1 draws the saved pencil marks and ?hint=1 highlights the
+// next hint. Responses carry an ETag over the puzzle and query, so
+// unchanged images revalidate with 304.
+func (h *Handler) handleRenderStored(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		fail(w, errMethod)
+		return
+	}
+	file := r.PathValue("file")
+	format := strings.TrimPrefix(path.Ext(file), ".")
+	id := strings.TrimSuffix(file, path.Ext(file))
+	if id == "" || (format != "svg" && format != "png") {
+		fail(w, domain.Errorf(domain.KindNotFound, "want /api/render/{id}.svg or .png"))
+		return
+	}
+	p, err := h.UC.Load(r.Context(), id)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	q := r.URL.Query()
+	opt := render.Options{Theme: q.Get("theme")}
+	opt.Size, _ = strconv.Atoi(q.Get("size"))
+	if flag(q.Get("solution")) {
+		givens := p.Givens()
+		if sol, _, err := h.UC.Solve(r.Context(), &givens); err == nil {
+			opt.Solution = sol
+		}
+	}
+	if flag(q.Get("marks")) {
+		opt.Marks = p.Marks
+	}
+	if flag(q.Get("hint")) {
+		if hh, found, err := h.UC.Hint(r.Context(), &p.Board, domain.StrategyUniqueness); err == nil && found {
+			opt.Hint = &hh
+		}
+	}
+	state, _ := json.Marshal(p)
+	h.writeImage(w, r, "public", etag(state, []byte(format+"?"+r.URL.RawQuery)), format, &p.Board, opt)
+}
+
+type renderReq struct {
+	Board    domain.Board  `json:"board"`
+	Marks    *domain.Marks `json:"marks,omitempty"`
+	Hint     *domain.Hint  `json:"hint,omitempty"`
+	Solution bool          `json:"solution,omitempty"`
+	Size     int           `json:"size,omitempty"`
+	Theme    string        `json:"theme,omitempty"`
+	Format   string        `json:"format,omitempty"` // svg (default) or png
+}
+
+// handleRender draws an ad-hoc board posted as a renderReq.
+func (h *Handler) handleRender(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		fail(w, errMethod)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+	var req renderReq
+	if err == nil {
+		err = json.Unmarshal(body, &req)
+	}
+	if err != nil {
+		fail(w, badJSON(err))
+		return
+	}
+	if req.Format == "" {
+		req.Format = "svg"
+	}
+	if req.Format != "svg" && req.Format != "png" {
+		fail(w, domain.Errorf(domain.KindInvalid, "format must be svg or png"))
+		return
+	}
+	if err := checkRenderReq(&req); err != nil {
+		fail(w, err)
+		return
+	}
+	opt := render.Options{Size: req.Size, Theme: req.Theme, Marks: req.Marks, Hint: req.Hint}
+	if req.Solution {
+		if sol, _, err := h.UC.Solve(r.Context(), &req.Board); err == nil {
+			opt.Solution = sol
+		}
+	}
+	h.writeImage(w, r, "private", etag(body), req.Format, &req.Board, opt)
+}
+
+// checkRenderReq rejects a posted board or hint that points off the grid.
+func checkRenderReq(req *renderReq) error {
+	cell := func(row, col int) bool { return row >= 0 && row < 9 && col >= 0 && col < 9 }
+	for r := range req.Board.Values {
+		for c, v := range req.Board.Values[r] {
+			if v > 9 {
+				return domain.Errorf(domain.KindInvalid, "r%dc%d: value %d is not a digit", r+1, c+1, v)
+			}
+		}
+	}
+	hint := req.Hint
+	if hint == nil {
+		return nil
+	}
+	if hs := hint.House; hs != nil {
+		switch hs.Kind {
+		case domain.HouseRow, domain.HouseCol, domain.HouseBox:
+		default:
+			return domain.Errorf(domain.KindInvalid, "hint house: unknown kind %q", hs.Kind)
+		}
+		if hs.Index < 0 || hs.Index > 8 {
+			return domain.Errorf(domain.KindInvalid, "hint house: index %d out of range 0-8", hs.Index)
+		}
+	}
+	cells := hint.Cells
+	if hint.Fish != nil {
+		cells = append(slices.Clip(cells), hint.Fish.Fins...)
+	}
+	for _, cc := range cells {
+		if !cell(cc.Row, cc.Col) {
+			return domain.Errorf(domain.KindInvalid, "hint cell (%d, %d) out of range 0-8", cc.Row, cc.Col)
+		}
+	}
+	for _, e := range hint.Eliminations {
+		if !cell(e.Row, e.Col) || e.Digit < 1 || e.Digit > 9 {
+			return domain.Errorf(domain.KindInvalid, "hint elimination (%d, %d, %d) out of range", e.Row, e.Col, e.Digit)
+		}
+	}
+	return nil
+}
+
+func (h *Handler) writeImage(w http.ResponseWriter, r *http.Request, scope, tag, format string, b *domain.Board, opt render.Options) {
+	w.Header().Set("ETag", tag)
+	w.Header().Set("Cache-Control", scope+", max-age=300")
+	if match := r.Header.Get("If-None-Match"); match != "" && strings.Contains(match, tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	var buf bytes.Buffer
+	var err error
+	if format == "png" {
+		w.Header().Set("Content-Type", "image/png")
+		err = render.PNG(&buf, b, opt)
+	} else {
+		w.Header().Set("Content-Type", "image/svg+xml")
+		err = render.SVG(&buf, b, opt)
+	}
+	if err != nil {
+		w.Header().Del("ETag")
+		w.Header().Del("Cache-Control")
+		fail(w, err)
+		return
+	}
+	_, _ = buf.WriteTo(w)
+}
+
+// etag hashes the inputs an image is drawn from into a strong validator.
+func etag(parts ...[]byte) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write(p)
+		h.Write([]byte{0})
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:12]) + `"`
+}
+
+func flag(s string) bool {
+	ok, _ := strconv.ParseBool(s)
+	return ok
+}
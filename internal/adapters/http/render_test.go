@@ -0,0 +1,32 @@
+package httpadapter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/usecase"
+)
+
+func TestRenderRejectsBadHint(t *testing.T) {
+	h := New(usecase.NewService(nil, nil, nil, nil, nil))
+	mux := http.NewServeMux()
+	h.Register(mux)
+	for _, body := range []string{
+		`{"hint":{"house":{"kind":"row","index":40}}}`,
+		`{"hint":{"house":{"kind":"box","index":-1}}}`,
+		`{"hint":{"cells":[{"row":0,"col":9}]}}`,
+		`{"hint":{"eliminations":[{"row":0,"col":0,"digit":10}]}}`,
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/render", strings.NewReader(body)))
+		var resp errorResp
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		if rec.Code != http.StatusBadRequest || resp.Code != domain.KindInvalid {
+			t.Errorf("%s: %d %s", body, rec.Code, rec.Body)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package httpadapter
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"svw.info/sudoku/internal/codec"
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/pdf"
+)
+
+// ---- Import / export ----
+
+type importResp struct {
+	Format string   `json:"format,omitempty"`
+	IDs    []string `json:"ids,omitempty"`
+}
+
+const maxImportBody = 8 << 20
+
+// handleImport saves every puzzle in the text body, detecting its format
+// (81-character lines, .sdm, .sdk, .ss or pencil-mark grids). Parse errors
+// answer 400 with the line and column of the offending input in the
+// details.
+func (h *Handler) handleImport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if r.Method != http.MethodPost {
+		fail(w, errMethod)
+		return
+	}
+	ps, f, err := h.UC.Import(r.Context(), http.MaxBytesReader(w, r.Body, maxImportBody))
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	resp := importResp{Format: string(f)}
+	for _, p := range ps {
+		resp.IDs = append(resp.IDs, p.ID)
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+type imageImportResp struct {
+	Board      *[9][9]uint8       `json:"board,omitempty"`
+	Confidence *[9][9]float64     `json:"confidence,omitempty"`
+	Uncertain  []domain.CellCoord `json:"uncertain,omitempty"`
+	Valid      bool               `json:"valid"`
+	Conflicts  []domain.CellCoord `json:"conflicts,omitempty"`
+	Unique     bool               `json:"unique"`
+}
+
+const maxImageBody = 16 << 20
+
+// handleImageImport recognises the board in a screenshot, posted as the
+// raw image or as the "image" field of a multipart form. The board comes
+// back with a confidence per cell and the uncertain cells listed, for the
+// player to confirm; it is not saved.
+func (h *Handler) handleImageImport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if r.Method != http.MethodPost {
+		fail(w, errMethod)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxImageBody)
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		f, _, err := r.FormFile("image")
+		if err != nil {
+			fail(w, domain.Errorf(domain.KindInvalid, "image field: %v", err))
+			return
+		}
+		defer f.Close()
+		body = f
+	}
+	res, err := h.UC.ImportImage(r.Context(), body)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(imageImportResp{
+		Board:      &res.Board.Values,
+		Confidence: &res.Confidence,
+		Uncertain:  res.Uncertain,
+		Valid:      res.Valid,
+		Conflicts:  res.Conflicts,
+		Unique:     res.Unique,
+	})
+}
+
+// handleExport writes stored puzzles as text: ?ids= is a comma-separated
+// list (all puzzles when omitted) and ?format= one of codec.Formats,
+// defaulting to line.
+func (h *Handler) handleExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		fail(w, errMethod)
+		return
+	}
+	q := r.URL.Query()
+	f := codec.FormatLine
+	if s := q.Get("format"); s != "" {
+		var ok bool
+		if f, ok = codec.ParseFormat(s); !ok {
+			fail(w, domain.Errorf(domain.KindInvalid, "unknown format %s", s))
+			return
+		}
+	}
+	var buf bytes.Buffer
+	if err := h.UC.Export(r.Context(), idList(q.Get("ids")), f, &buf); err != nil {
+		fail(w, err)
+		return
+	}
+	ext := string(f)
+	if f == codec.FormatLine || f == codec.FormatGrid {
+		ext = "txt"
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="puzzles.`+ext+`"`)
+	_, _ = buf.WriteTo(w)
+}
+
+// ---- Print ----
+
+// handlePrint answers a PDF booklet of the stored puzzles in ?ids= (all
+// when omitted), ?perPage= to a page, with an answer key. ?candidates=1
+// pencils in the candidates and ?title= heads every page.
+func (h *Handler) handlePrint(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		fail(w, errMethod)
+		return
+	}
+	q := r.URL.Query()
+	opt := pdf.Options{Title: q.Get("title")}
+	if s := q.Get("perPage"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > pdf.MaxPerPage {
+			fail(w, domain.Errorf(domain.KindInvalid, "perPage must be 1-%d", pdf.MaxPerPage))
+			return
+		}
+		opt.PerPage = n
+	}
+	opt.Candidates, _ = strconv.ParseBool(q.Get("candidates"))
+	var buf bytes.Buffer
+	if err := h.UC.Print(r.Context(), idList(q.Get("ids")), opt, &buf); err != nil {
+		fail(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `inline; filename="sudoku.pdf"`)
+	_, _ = buf.WriteTo(w)
+}
+
+// idList splits a comma-separated ?ids= value.
+func idList(s string) []string {
+	var ids []string
+	for _, id := range strings.Split(s, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,457 @@
+package httpadapter
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/render"
+	"svw.info/sudoku/internal/usecase"
+)
+
+// ---- API v1 ----
+
+// registerV1 mounts the resource-oriented API described by endpoints.
+// Method patterns let the mux answer 405 with an Allow header for other
+// methods by itself.
+func (h *Handler) registerV1(mux *http.ServeMux) {
+	for _, e := range endpoints {
+		handle := e.handle
+		mux.HandleFunc(e.method+" "+e.path, func(w http.ResponseWriter, r *http.Request) { handle(h, w, r) })
+	}
+	mux.HandleFunc("GET /api/openapi.json", h.handleOpenAPI)
+}
+
+// endpoint is a route of the v1 API with what the OpenAPI document says
+// about it.
+type endpoint struct {
+	method, path string
+	handle       func(*Handler, http.ResponseWriter, *http.Request)
+	summary      string
+	params       []param  // query parameters
+	body         any      // request body decoded as JSON; nil for none
+	bodyTypes    []string // other media types accepted as the body
+	resp         any      // response encoded as JSON, or each line of an NDJSON stream
+	respTypes    []string // media types of the response when not JSON
+	status       int      // success status; 0 means 200
+}
+
+// param is a query parameter, all of which are optional.
+type param struct {
+	name, typ, desc string // typ is an OpenAPI type: string, integer or boolean
+}
+
+var (
+	difficultyParam = param{"difficulty", "string", "easy, medium, hard or expert"}
+	maxTierParam    = param{"maxTier", "string", "hardest strategy used for rating: singles, pairs, advanced, xwing, chains, finned, uniqueness or lastresort"}
+	workersParam    = param{"workers", "integer", "lowers the number of puzzles checked at once"}
+	idsParam        = param{"ids", "string", "comma-separated puzzle IDs; all puzzles when omitted"}
+)
+
+// themeNames lists the keys of render.Themes for the theme parameter.
+func themeNames() string {
+	names := make([]string, 0, len(render.Themes))
+	for name := range render.Themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	last := len(names) - 1
+	return strings.Join(names[:last], ", ") + " or " + names[last] + "; light when omitted"
+}
+
+var endpoints = []endpoint{
+	{method: "GET", path: "/api/v1/puzzles", handle: (*Handler).listPuzzles,
+		summary: "List stored puzzles, newest first, a page at a time.",
+		params: []param{
+			difficultyParam,
+			{"q", "string", "case-insensitive text in the ID, name, collection or a tag"},
+			{"limit", "integer", "page size, 50 by default and at most 500"},
+			{"cursor", "string", "nextCursor of the previous page"},
+		},
+		resp: puzzleListResp{}},
+	{method: "POST", path: "/api/v1/puzzles", handle: (*Handler).createPuzzle,
+		summary: "Store a new puzzle under a fresh ID, or its own when not taken.",
+		body:    domain.Puzzle{}, resp: domain.Puzzle{}, status: http.StatusCreated},
+	{method: "GET", path: "/api/v1/puzzles/{id}", handle: (*Handler).getPuzzle,
+		summary: "Load a stored puzzle.",
+		resp:    domain.Puzzle{}},
+	{method: "PUT", path: "/api/v1/puzzles/{id}", handle: (*Handler).putPuzzle,
+		summary: "Store a puzzle under the ID, keeping its creation time; 201 when it is new.",
+		body:    domain.Puzzle{}, resp: domain.Puzzle{}},
+	{method: "PATCH", path: "/api/v1/puzzles/{id}", handle: (*Handler).patchPuzzle,
+		summary: "Change the name, notes, tags or favorite flag of a stored puzzle.",
+		body:    domain.PuzzlePatch{}, resp: domain.Puzzle{}},
+	{method: "DELETE", path: "/api/v1/puzzles/{id}", handle: (*Handler).deletePuzzle,
+		summary: "Delete a stored puzzle. It stays restorable for the server's trash period, if any.",
+		resp:    deleteResp{}},
+	{method: "POST", path: "/api/v1/puzzles/{id}/restore", handle: (*Handler).restorePuzzle,
+		summary: "Restore a deleted puzzle from the trash.",
+		resp:    domain.Puzzle{}},
+	{method: "POST", path: "/api/v1/puzzles/{id}/solve", handle: (*Handler).solvePuzzle,
+		summary: "Solve the givens of a stored puzzle.",
+		resp:    solveResp{}},
+	{method: "POST", path: "/api/v1/puzzles/{id}/hint", handle: (*Handler).hintPuzzle,
+		summary: "Next step on a stored puzzle; repeated requests disclose more. The body may be empty.",
+		body:    puzzleHintReq{}, resp: hintResp{}},
+	{method: "GET", path: "/api/v1/puzzles/{id}/check", handle: (*Handler).checkPuzzle,
+		summary: "Compare a stored puzzle's entries with the solution.",
+		resp:    validateResp{}},
+	{method: "GET", path: "/api/v1/render/{file}", handle: (*Handler).handleRenderStored,
+		summary: "Draw a stored puzzle; file is {id}.svg or {id}.png.",
+		params: []param{
+			{"size", "integer", "edge length in pixels"},
+			{"theme", "string", themeNames()},
+			{"solution", "boolean", "fill in the solution"},
+			{"marks", "boolean", "draw the saved pencil marks"},
+			{"hint", "boolean", "highlight the next hint"},
+		},
+		respTypes: []string{"image/svg+xml", "image/png"}},
+	{method: "POST", path: "/api/v1/render", handle: (*Handler).handleRender,
+		summary: "Draw the posted board.",
+		body:    renderReq{}, respTypes: []string{"image/svg+xml", "image/png"}},
+
+	{method: "POST", path: "/api/v1/generate", handle: (*Handler).handleGenerate,
+		summary: "Generate a puzzle with a unique solution. The body may be empty.",
+		body:    generateReq{}, resp: generateResp{}},
+	{method: "POST", path: "/api/v1/solve", handle: (*Handler).handleSolve,
+		summary: "Solve the posted board.",
+		body:    solveReq{}, resp: solveResp{}},
+	{method: "POST", path: "/api/v1/validate", handle: (*Handler).handleValidate,
+		summary: "Check the posted board for conflicts, wrong entries or dead ends.",
+		body:    validateReq{}, resp: validateResp{}},
+	{method: "POST", path: "/api/v1/marks/check", handle: (*Handler).handleMarksCheck,
+		summary: "Compare pencil marks with the true candidates.",
+		body:    marksReq{}, resp: marksResp{}},
+	{method: "POST", path: "/api/v1/hint", handle: (*Handler).handleHint,
+		summary: "Next logical step on the posted board.",
+		body:    hintReq{}, resp: hintResp{}},
+	{method: "POST", path: "/api/v1/batch", handle: (*Handler).handleBatch,
+		summary: "Validate, solve and rate many boards, streaming a result per board in completion order.",
+		params:  []param{workersParam, maxTierParam},
+		body:    batchReq{}, bodyTypes: []string{"text/plain"},
+		resp: usecase.BatchResult{}, respTypes: []string{"application/x-ndjson"}},
+
+	{method: "POST", path: "/api/v1/import", handle: (*Handler).handleImport,
+		summary:   "Store every puzzle in the body, detecting its text format.",
+		bodyTypes: []string{"text/plain"}, resp: importResp{}},
+	{method: "POST", path: "/api/v1/import/image", handle: (*Handler).handleImageImport,
+		summary:   "Recognise the board in a screenshot, posted raw or as the image field of a form. Nothing is stored.",
+		bodyTypes: []string{"image/png", "image/jpeg", "image/gif", "multipart/form-data"},
+		resp:      imageImportResp{}},
+	{method: "GET", path: "/api/v1/export", handle: (*Handler).handleExport,
+		summary: "Write stored puzzles as text.",
+		params: []param{
+			idsParam,
+			{"format", "string", "line (default), sdm, sdk, ss, pm or grid"},
+		},
+		respTypes: []string{"text/plain"}},
+	{method: "GET", path: "/api/v1/print", handle: (*Handler).handlePrint,
+		summary: "PDF booklet of stored puzzles with an answer key.",
+		params: []param{
+			idsParam,
+			{"title", "string", "heading of every page"},
+			{"perPage", "integer", "puzzles to a page"},
+			{"candidates", "boolean", "pencil in the candidates"},
+		},
+		respTypes: []string{"application/pdf"}},
+	{method: "POST", path: "/api/v1/collections/{name}/import", handle: (*Handler).handleCollectionImport,
+		summary: "Bulk-import a collection, streaming a report after every checkpoint with the issues new since the last; the last has done or an error.",
+		params: []param{
+			{"tags", "string", "comma-separated tags for every puzzle"},
+			maxTierParam,
+			workersParam,
+			{"after", "integer", "line of the last report received, to resume an import"},
+			{"imported", "integer", "imported count of the last report received, carried on by a resume"},
+		},
+		bodyTypes: []string{"text/plain"},
+		resp:      usecase.ImportReport{}, respTypes: []string{"application/x-ndjson"}},
+
+	{method: "GET", path: "/api/v1/daily", handle: (*Handler).handleDaily,
+		summary: "The daily challenge.",
+		params: []param{
+			difficultyParam,
+			{"date", "string", "day as YYYY-MM-DD; today when omitted"},
+		},
+		resp: dailyResp{}},
+	{method: "GET", path: "/api/v1/daily/archive", handle: (*Handler).handleDailyArchive,
+		summary: "Past daily challenges.",
+		params:  []param{difficultyParam},
+		resp:    dailyArchiveResp{}},
+	{method: "POST", path: "/api/v1/games", handle: (*Handler).handleGames,
+		summary: "Start a game from a stored puzzle or a board.",
+		body:    startGameReq{}, resp: gameResp{}, status: http.StatusCreated},
+	{method: "GET", path: "/api/v1/games/{id}", handle: (*Handler).handleGame,
+		summary: "A game with its move log.",
+		resp:    gameResp{}},
+	{method: "POST", path: "/api/v1/games/{id}/moves", handle: (*Handler).handleGameMove,
+		summary: "Play a move.",
+		body:    moveReq{}, resp: gameResp{}},
+	{method: "POST", path: "/api/v1/games/{id}/undo", handle: (*Handler).handleGameUndo,
+		summary: "Undo the latest move in effect.",
+		resp:    gameResp{}},
+	{method: "POST", path: "/api/v1/games/{id}/redo", handle: (*Handler).handleGameRedo,
+		summary: "Redo the latest undone move.",
+		resp:    gameResp{}},
+	{method: "GET", path: "/api/v1/games/{id}/replay", handle: (*Handler).handleGameReplay,
+		summary: "Step-by-step replay of a game with timing analytics.",
+		resp:    replayResp{}},
+}
+
+// deprecated marks a response of the unversioned API as superseded by
+// /api/v1.
+func deprecated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", `</api/v1>; rel="successor-version"`)
+		next(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+type puzzleListResp struct {
+	Puzzles    []domain.PuzzleMeta `json:"puzzles"`
+	NextCursor string              `json:"nextCursor,omitempty"`
+}
+
+// listPuzzles pages through the stored puzzles, newest first.
+// ?difficulty= and ?q= filter them, ?limit= sets the page size and ?cursor=
+// continues from the nextCursor of the previous page.
+func (h *Handler) listPuzzles(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := usecase.PuzzleQuery{Text: q.Get("q"), Cursor: q.Get("cursor")}
+	if s := q.Get("difficulty"); s != "" {
+		d := parseDifficulty(s)
+		if !strings.EqualFold(d.String(), strings.TrimSpace(s)) {
+			fail(w, domain.Errorf(domain.KindInvalid, "difficulty must be easy, medium, hard or expert"))
+			return
+		}
+		query.Difficulty = &d
+	}
+	if s := q.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			fail(w, domain.Errorf(domain.KindInvalid, "limit must be a positive number"))
+			return
+		}
+		query.Limit = n
+	}
+	page, err := h.UC.FindPuzzles(r.Context(), query)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, puzzleListResp{Puzzles: page.Puzzles, NextCursor: page.Next})
+}
+
+func (h *Handler) getPuzzle(w http.ResponseWriter, r *http.Request) {
+	p, err := h.UC.Load(r.Context(), r.PathValue("id"))
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+// createPuzzle saves a new puzzle under a fresh ID, or the one in the body
+// when that is not taken yet, and answers 201 with its location.
+func (h *Handler) createPuzzle(w http.ResponseWriter, r *http.Request) {
+	p, ok := decodePuzzle(w, r)
+	if !ok {
+		return
+	}
+	if p.ID == "" {
+		p.ID = strconv.FormatInt(time.Now().UnixNano(), 10)
+	} else if _, err := h.UC.Load(r.Context(), p.ID); err == nil {
+		fail(w, domain.Errorf(domain.KindConflict, "puzzle %s already exists", p.ID))
+		return
+	}
+	if p.CreatedAt == 0 {
+		p.CreatedAt = time.Now().UnixNano()
+	}
+	if err := h.UC.Save(r.Context(), p); err != nil {
+		fail(w, err)
+		return
+	}
+	w.Header().Set("Location", "/api/v1/puzzles/"+p.ID)
+	writeJSON(w, http.StatusCreated, p)
+}
+
+// putPuzzle stores the body under the ID in the path, replacing any puzzle
+// saved there while keeping its creation time. It answers 201 when the
+// puzzle is new.
+func (h *Handler) putPuzzle(w http.ResponseWriter, r *http.Request) {
+	p, ok := decodePuzzle(w, r)
+	if !ok {
+		return
+	}
+	p.ID = r.PathValue("id")
+	status := http.StatusOK
+	old, err := h.UC.Load(r.Context(), p.ID)
+	switch {
+	case err == nil:
+		if p.CreatedAt == 0 {
+			p.CreatedAt = old.CreatedAt
+		}
+	case errors.Is(err, domain.ErrNotFound):
+		status = http.StatusCreated
+	default:
+		fail(w, err)
+		return
+	}
+	if p.CreatedAt == 0 {
+		p.CreatedAt = time.Now().UnixNano()
+	}
+	if err := h.UC.Save(r.Context(), p); err != nil {
+		fail(w, err)
+		return
+	}
+	writeJSON(w, status, p)
+}
+
+// patchPuzzle changes the name, notes, tags or favorite flag of a stored
+// puzzle, leaving fields missing from the body as they are.
+func (h *Handler) patchPuzzle(w http.ResponseWriter, r *http.Request) {
+	var patch domain.PuzzlePatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		fail(w, badJSON(err))
+		return
+	}
+	p, err := h.UC.Update(r.Context(), r.PathValue("id"), patch)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+// deleteResp tells until when a deleted puzzle can be restored, in Unix
+// nanoseconds like createdAt; it is absent when the server keeps no trash.
+type deleteResp struct {
+	ID              string `json:"id"`
+	RestorableUntil int64  `json:"restorableUntil,omitempty"`
+}
+
+func (h *Handler) deletePuzzle(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	until, err := h.UC.Delete(r.Context(), id)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	resp := deleteResp{ID: id}
+	if !until.IsZero() {
+		resp.RestorableUntil = until.UnixNano()
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// restorePuzzle undoes a delete while the puzzle is still in the trash.
+func (h *Handler) restorePuzzle(w http.ResponseWriter, r *http.Request) {
+	p, err := h.UC.Restore(r.Context(), r.PathValue("id"))
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+func decodePuzzle(w http.ResponseWriter, r *http.Request) (*domain.Puzzle, bool) {
+	var p domain.Puzzle
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		fail(w, badJSON(err))
+		return nil, false
+	}
+	return &p, true
+}
+
+// solvePuzzle solves the givens of a stored puzzle.
+func (h *Handler) solvePuzzle(w http.ResponseWriter, r *http.Request) {
+	p, err := h.UC.Load(r.Context(), r.PathValue("id"))
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	givens := p.Givens()
+	out, st, err := h.UC.Solve(r.Context(), &givens)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, solveResp{Board: out.Values, DurationMs: st.Duration.Milliseconds(), Nodes: st.Nodes})
+}
+
+// puzzleHintReq is hintReq without the board, which comes from the stored
+// puzzle. The body may be empty.
+type puzzleHintReq struct {
+	MaxTier    string           `json:"maxTier,omitempty"`
+	Session    string           `json:"session,omitempty"`
+	GameID     string           `json:"gameId,omitempty"`
+	Level      domain.HintLevel `json:"level,omitempty"`
+	Candidates *domain.Marks    `json:"candidates,omitempty"`
+	Fallback   bool             `json:"fallback,omitempty"`
+}
+
+// hintPuzzle gives the next step on a stored puzzle's current board. The
+// session defaults to the puzzle ID, so repeated requests escalate.
+func (h *Handler) hintPuzzle(w http.ResponseWriter, r *http.Request) {
+	var req puzzleHintReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		fail(w, badJSON(err))
+		return
+	}
+	id := r.PathValue("id")
+	p, err := h.UC.Load(r.Context(), id)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	if req.Session == "" && req.GameID == "" {
+		req.Session = "puzzle:" + id
+	}
+	max, err := parseTier(req.MaxTier, domain.StrategySingles)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	b := &domain.Board{Values: p.Board.Values}
+	hh, ok, used, err := h.UC.GradedHint(r.Context(), req.Session, req.GameID, b, req.Candidates, max, req.Level, req.Fallback)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, hintResp{Found: ok, Hint: hh, HintsUsed: used})
+}
+
+// checkPuzzle compares a stored puzzle's entries with the solution of its
+// givens.
+func (h *Handler) checkPuzzle(w http.ResponseWriter, r *http.Request) {
+	p, err := h.UC.Load(r.Context(), r.PathValue("id"))
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	b := p.Board
+	b.Fixed = p.Givens().Fixed
+	chk, err := h.UC.CheckSolution(r.Context(), &b)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, validateResp{
+		OK:        len(chk.Conflicts) == 0 && len(chk.Wrong) == 0,
+		Conflicts: chk.Conflicts,
+		Wrong:     chk.Wrong,
+		Solvable:  &chk.Solvable,
+	})
+}
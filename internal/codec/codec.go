@@ -0,0 +1,416 @@
+// Package codec reads and writes puzzles in plain-text formats.
+package codec
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"svw.info/sudoku/internal/domain"
+)
+
+// Format names a text format for puzzles.
+type Format string
+
+const (
+	FormatLine Format = "line" // 81 characters per puzzle, . for empty cells
+	FormatSDM  Format = "sdm"  // SadMan collection: 81 characters, 0 for empty cells
+	FormatSDK  Format = "sdk"  // SadMan puzzle: #-headers and nine rows of nine cells
+	FormatSS   Format = "ss"   // Simple Sudoku: rows like |53.|.7.|...| inside a border
+	FormatPM   Format = "pm"   // HoDoKu / Sudoku Explainer grid with pencil marks
+	FormatGrid Format = "grid" // nine spaced rows as printed by Grid
+)
+
+// Formats lists every format in the order they are offered to users.
+var Formats = []Format{FormatLine, FormatSDM, FormatSDK, FormatSS, FormatPM, FormatGrid}
+
+// ParseFormat accepts a format name, ignoring case and a leading dot.
+func ParseFormat(s string) (Format, bool) {
+	s = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(s), "."))
+	for _, f := range Formats {
+		if s == string(f) {
+			return f, true
+		}
+	}
+	return "", false
+}
+
+// Multiline reports whether f draws each puzzle over several lines.
+func (f Format) Multiline() bool {
+	return f != FormatLine && f != FormatSDM
+}
+
+// Error locates a problem in the input. Col is 0 when it concerns the whole
+// line.
+type Error struct {
+	Line int
+	Col  int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.Line == 0:
+		return fmt.Sprintf("column %d: %s", e.Col, e.Msg)
+	case e.Col == 0:
+		return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+	}
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Col, e.Msg)
+}
+
+// ParseLine reads one puzzle written as 81 characters: digits 1-9, with 0
+// or . for empty cells.
+func ParseLine(s string) (domain.Board, error) {
+	var b domain.Board
+	if len(s) != 81 {
+		return b, fmt.Errorf("want 81 cells, got %d", len(s))
+	}
+	for i := 0; i < 81; i++ {
+		v, ok := cellValue(s[i])
+		if !ok {
+			return b, &Error{Col: i + 1, Msg: fmt.Sprintf("unexpected %q", s[i])}
+		}
+		b.Values[i/9][i%9] = v
+	}
+	return b, nil
+}
+
+func cellValue(ch byte) (uint8, bool) {
+	switch {
+	case ch >= '1' && ch <= '9':
+		return ch - '0', true
+	case ch == '0' || ch == '.':
+		return 0, true
+	}
+	return 0, false
+}
+
+// isSeparator reports characters used to draw grids around the cells.
+func isSeparator(ch byte) bool {
+	return strings.IndexByte(" \t|+-=:*'", ch) >= 0
+}
+
+// cellChars counts the characters of raw that are not separators: 81 or
+// more for a puzzle on one line, fewer for a row of a grid.
+func cellChars(raw string) int {
+	n := 0
+	for i := 0; i < len(raw); i++ {
+		if !isSeparator(raw[i]) {
+			n++
+		}
+	}
+	return n
+}
+
+// isBorder reports rows drawn only of separators, like "*-----------*" or
+// HoDoKu's ".------.------.", whose corners would otherwise read as cells.
+func isBorder(text string) bool {
+	dashes := 0
+	for i := 0; i < len(text); i++ {
+		switch ch := text[i]; {
+		case ch == '-' || ch == '=':
+			dashes++
+		case ch != '.' && !isSeparator(ch):
+			return false
+		}
+	}
+	return dashes >= 3
+}
+
+// Read reads every puzzle in r as a board of values only; see Decode for the
+// accepted formats.
+func Read(r io.Reader) ([]domain.Board, error) {
+	ps, _, err := Decode(r)
+	out := make([]domain.Board, len(ps))
+	for i := range ps {
+		out[i] = domain.Board{Values: ps[i].Board.Values}
+	}
+	return out, err
+}
+
+// cell is one parsed cell: a value, or the candidates of a pencil-mark grid.
+type cell struct {
+	v     uint8
+	cands domain.Digits
+}
+
+// Decoder reads puzzles one at a time, so long collections need not be held
+// in memory and a malformed puzzle can be skipped. See Decode for the
+// accepted formats.
+type Decoder struct {
+	sc     *bufio.Scanner
+	line   int
+	ready  []domain.Puzzle // decoded but not yet returned by Next
+	format Format          // format of the first puzzle
+	rows   [][]cell        // rows of the grid being read
+	name   string          // SadMan headers preceding the grid
+	notes  []string
+
+	pm, spaced, boxed bool // how the rows of the current grid were drawn
+	skip              bool // dropping the rest of a grid after an error
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{sc: bufio.NewScanner(r)}
+}
+
+// Line returns the number of the last line read, which ends the puzzle or
+// error last returned by Next.
+func (d *Decoder) Line() int { return d.line }
+
+// Format returns the format of the first puzzle decoded so far.
+func (d *Decoder) Format() Format { return d.format }
+
+// Next returns the next puzzle, or io.EOF at the end of the input. After an
+// *Error in a grid the rest of that grid is dropped up to the next blank
+// line, # header, top border or 81-cell line, so its remaining rows are not
+// read as the start of the next puzzle. A blank line ends a grid.
+func (d *Decoder) Next() (domain.Puzzle, error) {
+	for len(d.ready) == 0 {
+		if !d.sc.Scan() {
+			if err := d.sc.Err(); err != nil {
+				return domain.Puzzle{}, err
+			}
+			if n := len(d.rows); n > 0 {
+				d.reset()
+				return domain.Puzzle{}, &Error{Line: d.line, Msg: fmt.Sprintf("grid ends after %d rows", n)}
+			}
+			return domain.Puzzle{}, io.EOF
+		}
+		d.line++
+		raw := d.sc.Text()
+		if d.line == 1 {
+			raw = strings.TrimPrefix(raw, "\ufeff") // byte order mark
+		}
+		text := strings.TrimSpace(raw)
+		switch {
+		case text == "":
+			d.skip = false
+			if n := len(d.rows); n > 0 {
+				d.reset()
+				return domain.Puzzle{}, &Error{Line: d.line, Msg: fmt.Sprintf("grid ends after %d rows", n)}
+			}
+			continue
+		case isBorder(text):
+			// the top borders of Simple Sudoku and pencil-mark grids;
+			// band separators inside a grid start with | or :
+			if text[0] == '*' || text[0] == '.' {
+				d.skip = false
+			}
+			continue
+		case text[0] == '#':
+			d.skip = false
+			d.header(text)
+			continue
+		case text[0] == '[' && text[len(text)-1] == ']':
+			continue
+		case d.skip && cellChars(raw) < 81:
+			continue
+		}
+		d.skip = false
+		if err := d.row(raw, d.line); err != nil {
+			d.skip = len(d.rows) > 0 || cellChars(raw) < 81
+			d.reset()
+			return domain.Puzzle{}, err
+		}
+	}
+	p := d.ready[0]
+	d.ready = d.ready[1:]
+	return p, nil
+}
+
+// reset drops the grid being read and its headers.
+func (d *Decoder) reset() {
+	d.rows = d.rows[:0]
+	d.name, d.notes = "", nil
+	d.pm, d.spaced, d.boxed = false, false, false
+}
+
+// Decode reads every puzzle in r and reports the format of the first one.
+// Puzzles may be 81-character lines (sdm when blanks are written as 0) or
+// grids of nine rows: compact rows with optional SadMan headers (#D is the
+// name, #C a note), Simple Sudoku's boxed rows, spaced rows, or the
+// pencil-mark grids of HoDoKu and Sudoku Explainer where a cell with several
+// digits lists its candidates. Given values are marked Fixed. Text after
+// the 81 cells of a line, such as a rating or comment, is ignored. Blank
+// lines, [section] lines and other # lines are skipped. Decoding stops at
+// the first error; syntax errors are *Error.
+func Decode(r io.Reader) ([]domain.Puzzle, Format, error) {
+	d := NewDecoder(r)
+	var out []domain.Puzzle
+	for {
+		p, err := d.Next()
+		if err == io.EOF {
+			return out, d.format, nil
+		}
+		if err != nil {
+			return out, d.format, err
+		}
+		out = append(out, p)
+	}
+}
+
+func (d *Decoder) header(text string) {
+	if len(text) < 2 {
+		return
+	}
+	val := strings.TrimSpace(text[2:])
+	switch text[1] {
+	case 'D':
+		d.name = val
+	case 'C':
+		if val != "" {
+			d.notes = append(d.notes, val)
+		}
+	}
+}
+
+// field is a run of non-separator characters and the column it starts at.
+type field struct {
+	text string
+	col  int
+}
+
+func fields(raw string) []field {
+	var out []field
+	start := -1
+	for i := 0; i <= len(raw); i++ {
+		if i < len(raw) && !isSeparator(raw[i]) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			out = append(out, field{raw[start:i], start + 1})
+			start = -1
+		}
+	}
+	return out
+}
+
+// candidates parses a pencil-mark field of digits 1-9. A lone candidate is
+// bracketed, as in [5], to tell it from a placed value, and . is a cell
+// with none left.
+func candidates(s string) (domain.Digits, bool) {
+	var ds domain.Digits
+	if s == "." {
+		return 0, true
+	}
+	if len(s) == 3 && s[0] == '[' && s[2] == ']' {
+		s = s[1:2]
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '1' || s[i] > '9' {
+			return 0, false
+		}
+		ds = ds.With(s[i] - '0')
+	}
+	return ds, true
+}
+
+func (d *Decoder) row(raw string, line int) error {
+	fs := fields(raw)
+	var cells []cell
+	// Pencil-mark rows have nine fields of digits, at least one of them a
+	// list of candidates.
+	pm, marked := len(fs) == 9, false
+	for _, f := range fs {
+		if _, ok := candidates(f.text); !ok {
+			pm = false
+		}
+		marked = marked || len(f.text) > 1
+	}
+	pm = pm && marked
+	if pm {
+		for _, f := range fs {
+			ds, _ := candidates(f.text)
+			if v, ok := ds.Single(); ok && len(f.text) == 1 {
+				cells = append(cells, cell{v: v})
+			} else {
+				cells = append(cells, cell{cands: ds})
+			}
+		}
+		d.pm = true
+	} else {
+		// a line's 81 cells may be followed by a rating or comment
+		end := len(raw)
+	scan:
+		for _, f := range fs {
+			for i := 0; i < len(f.text); i++ {
+				if len(cells) == 81 && len(d.rows) == 0 {
+					end = f.col - 1 + i
+					break scan
+				}
+				v, ok := cellValue(f.text[i])
+				if !ok {
+					return &Error{Line: line, Col: f.col + i, Msg: fmt.Sprintf("unexpected %q", f.text[i])}
+				}
+				cells = append(cells, cell{v: v})
+			}
+		}
+		raw = raw[:end]
+	}
+
+	switch n := len(cells); {
+	case n == 81 && len(d.rows) == 0:
+		f := FormatLine
+		if !strings.Contains(raw, ".") && strings.Contains(raw, "0") {
+			f = FormatSDM
+		}
+		d.emit(f, cells)
+	case n == 9:
+		if len(fs) == 9 && !pm {
+			d.spaced = true
+		}
+		if strings.Contains(raw, "|") && len(fs) == 3 {
+			d.boxed = true
+		}
+		d.rows = append(d.rows, cells)
+		if len(d.rows) == 9 {
+			f := FormatSDK
+			switch {
+			case d.pm:
+				f = FormatPM
+			case d.boxed:
+				f = FormatSS
+			case d.spaced:
+				f = FormatGrid
+			}
+			var all []cell
+			for _, r := range d.rows {
+				all = append(all, r...)
+			}
+			d.emit(f, all)
+		}
+	default:
+		return &Error{Line: line, Msg: fmt.Sprintf("want 9 or 81 cells, got %d", n)}
+	}
+	return nil
+}
+
+// emit finishes a puzzle from 81 cells and the pending headers.
+func (d *Decoder) emit(f Format, cells []cell) {
+	p := domain.Puzzle{Name: d.name, Notes: strings.Join(d.notes, "\n")}
+	var marks domain.Marks
+	hasMarks := false
+	for i, c := range cells {
+		r, col := i/9, i%9
+		if c.v != 0 {
+			p.Board.Values[r][col] = c.v
+			p.Board.Fixed[r][col] = true
+		} else if c.cands != 0 {
+			marks[r][col] = c.cands
+			hasMarks = true
+		}
+	}
+	if hasMarks {
+		p.Marks = &marks
+	}
+	if d.format == "" {
+		d.format = f
+	}
+	d.ready = append(d.ready, p)
+	d.reset()
+}
@@ -0,0 +1,184 @@
+package codec
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"svw.info/sudoku/internal/domain"
+)
+
+const line = "53..7....6..195....98....6.8...6...34..8.3..17...2...6.6....28....419..5....8..79"
+
+func TestReadLinesAndGrids(t *testing.T) {
+	b, err := ParseLine(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := "# two puzzles\n" + line + "\n\n" + Grid(&b)
+	boards, err := Read(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(boards) != 2 || boards[0] != b || boards[1] != b {
+		t.Fatalf("read %d boards: %v", len(boards), boards)
+	}
+	if got := Line(&boards[1]); got != line {
+		t.Fatalf("Line = %q", got)
+	}
+}
+
+func TestReadBOMAndComments(t *testing.T) {
+	in := "\ufeff" + line + " # 2.3 SE rating\n" + strings.ReplaceAll(line, ".", "0") + ";from a forum\n"
+	ps, f, err := Decode(strings.NewReader(in))
+	if err != nil || f != FormatLine || len(ps) != 2 {
+		t.Fatalf("Decode = %d puzzles, %s, %v", len(ps), f, err)
+	}
+	for _, p := range ps {
+		if Line(&p.Board) != line {
+			t.Fatalf("board %s", Line(&p.Board))
+		}
+	}
+	if _, f, _ := Decode(strings.NewReader(strings.ReplaceAll(line, ".", "0") + " rated 1.5\n")); f != FormatSDM {
+		t.Fatalf("trailing comment changed the format to %s", f)
+	}
+}
+
+func TestDecoderSkipsRestOfBadGrid(t *testing.T) {
+	b, _ := ParseLine(line)
+	other := b
+	other.Values[0], other.Values[3] = b.Values[3], b.Values[0] // swapped bands stay valid
+	bad := strings.Split(SDK(&domain.Puzzle{Board: b}), "\n")
+	bad[2] = "..x......"
+	for name, in := range map[string]string{
+		"blank lines": strings.Join(bad, "\n") + "\n" + SDK(&domain.Puzzle{Board: other}) + "\n" + SS(&b),
+		"headers":     strings.Join(bad, "\n") + SDK(&domain.Puzzle{Name: "two", Board: other}) + SS(&b),
+	} {
+		d := NewDecoder(strings.NewReader(in))
+		var got []domain.Board
+		var errs []error
+		for {
+			p, err := d.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			got = append(got, domain.Board{Values: p.Board.Values})
+		}
+		if len(errs) != 1 || errs[0].Error() != "line 3, column 3: unexpected 'x'" {
+			t.Fatalf("%s: errors %v", name, errs)
+		}
+		if len(got) != 2 || got[0].Values != other.Values || got[1].Values != b.Values {
+			t.Fatalf("%s: decoded %d boards: %v", name, len(got), got)
+		}
+	}
+
+	// a blank line ends a grid
+	in := strings.Join(bad[:2], "\n") + "\n\n" + line + "\n"
+	ps, _, err := Decode(strings.NewReader(in))
+	if err == nil || err.Error() != "line 3: grid ends after 2 rows" || len(ps) != 0 {
+		t.Fatalf("Decode = %d puzzles, %v", len(ps), err)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	for in, want := range map[string]string{
+		line[:80] + "x\n":        "line 1, column 81: unexpected 'x'",
+		"53..7....\n6..19x...\n": "line 2, column 6: unexpected 'x'",
+		line[:40] + "\n":         "line 1: want 9 or 81 cells, got 40",
+		"123456789\n123456789\n": "line 2: grid ends after 2 rows",
+	} {
+		if _, err := Read(strings.NewReader(in)); err == nil || err.Error() != want {
+			t.Errorf("Read(%q) error = %v, want %q", in, err, want)
+		}
+	}
+}
+
+func TestEncodeDecodeFormats(t *testing.T) {
+	b, _ := ParseLine(line)
+	in := []domain.Puzzle{{Name: "Wikipedia", Notes: "classic", Board: b}, {Board: b}}
+	for _, f := range Formats {
+		var sb strings.Builder
+		if err := Encode(&sb, f, in); err != nil {
+			t.Fatal(err)
+		}
+		out, got, err := Decode(strings.NewReader(sb.String()))
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", f, err, sb.String())
+		}
+		if got != f || len(out) != 2 {
+			t.Fatalf("%s: detected %s with %d puzzles", f, got, len(out))
+		}
+		if Line(&out[1].Board) != line || !out[1].Board.Fixed[0][0] || out[1].Board.Fixed[0][2] {
+			t.Fatalf("%s: board %s", f, Line(&out[1].Board))
+		}
+		if f == FormatSDK && (out[0].Name != "Wikipedia" || out[0].Notes != "classic") {
+			t.Fatalf("sdk headers: %q %q", out[0].Name, out[0].Notes)
+		}
+	}
+}
+
+func TestDecodePencilMarks(t *testing.T) {
+	const pm = `.-------------------.----------------.-------------------.
+| 5    3     124    | 26    7   2468 | 1489   1249  248  |
+| 6    247   247    | 1     9   5    | 3478   234   2478 |
+| 12   9     8      | 23    34  24   | 13457  6     247  |
+:-------------------+----------------+-------------------:
+| 8    125   1259   | 579   6   147  | 4579   2459  3    |
+| 4    25    2569   | 8     5   3    | 579    259   1    |
+| 7    15    1359   | 59    2   14   | 4589   459   6    |
+:-------------------+----------------+-------------------:
+| 139  6     134579 | 357   35  7    | 2      8     4    |
+| 23   278   237    | 4     1   9    | 36     3     5    |
+| 123  1245  12345  | 2356  8   26   | 1346   7     9    |
+'-------------------'----------------'-------------------'
+`
+	ps, f, err := Decode(strings.NewReader(pm))
+	if err != nil || f != FormatPM || len(ps) != 1 {
+		t.Fatalf("Decode = %d puzzles, %s, %v", len(ps), f, err)
+	}
+	p := ps[0]
+	if p.Board.Values[0][0] != 5 || p.Board.Values[4][4] != 5 || p.Marks == nil {
+		t.Fatalf("values %v", p.Board.Values)
+	}
+	if got := p.Marks[0][2]; got != domain.DigitsOf(1, 2, 4) {
+		t.Fatalf("r1c3 marks = %v", got.List())
+	}
+	if got := PM(&p.Board, p.Marks); got != pm {
+		t.Fatalf("PM round trip:\n%s", got)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if f, ok := ParseFormat(".SDK"); !ok || f != FormatSDK {
+		t.Fatalf("ParseFormat(.SDK) = %q, %v", f, ok)
+	}
+	if _, ok := ParseFormat("csv"); ok {
+		t.Fatal("csv accepted")
+	}
+}
+
+func TestPencilMarksRoundTripSingles(t *testing.T) {
+	b, _ := ParseLine(line)
+	var marks domain.Marks
+	marks[0][2] = domain.DigitsOf(4) // a lone candidate, not a placed 4
+	p := domain.Puzzle{Board: b, Marks: &marks}
+	out := PM(&p.Board, p.Marks)
+	if !strings.Contains(out, "[4]") {
+		t.Fatalf("lone candidate not bracketed:\n%s", out)
+	}
+	ps, f, err := Decode(strings.NewReader(out))
+	if err != nil || f != FormatPM || len(ps) != 1 {
+		t.Fatalf("Decode = %d puzzles, %s, %v", len(ps), f, err)
+	}
+	got := ps[0]
+	if got.Board.Values != b.Values || got.Marks[0][2] != domain.DigitsOf(4) {
+		t.Fatalf("read back %s with r1c3 marks %v", Line(&got.Board), got.Marks[0][2].List())
+	}
+	if again := PM(&got.Board, got.Marks); again != out {
+		t.Fatalf("second export differs:\n%s", again)
+	}
+}
@@ -0,0 +1,177 @@
+package codec
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"svw.info/sudoku/internal/domain"
+)
+
+// Encode writes ps to w in format f. Multi-line formats separate puzzles
+// with a blank line.
+func Encode(w io.Writer, f Format, ps []domain.Puzzle) error {
+	bw := bufio.NewWriter(w)
+	for i := range ps {
+		p := &ps[i]
+		if i > 0 && f.Multiline() {
+			bw.WriteByte('\n')
+		}
+		switch f {
+		case FormatLine:
+			bw.WriteString(Line(&p.Board) + "\n")
+		case FormatSDM:
+			bw.WriteString(strings.ReplaceAll(Line(&p.Board), ".", "0") + "\n")
+		case FormatSDK:
+			bw.WriteString(SDK(p))
+		case FormatSS:
+			bw.WriteString(SS(&p.Board))
+		case FormatPM:
+			bw.WriteString(PM(&p.Board, p.Marks))
+		case FormatGrid:
+			bw.WriteString(Grid(&p.Board))
+		default:
+			return fmt.Errorf("unknown format %q", f)
+		}
+	}
+	return bw.Flush()
+}
+
+// Line formats b as 81 characters with . for empty cells.
+func Line(b *domain.Board) string {
+	var sb strings.Builder
+	for r := 0; r < 9; r++ {
+		for c := 0; c < 9; c++ {
+			sb.WriteByte(cellChar(b.Values[r][c]))
+		}
+	}
+	return sb.String()
+}
+
+// Grid formats b as nine rows with box separators, ending in a newline.
+func Grid(b *domain.Board) string {
+	var sb strings.Builder
+	for r := 0; r < 9; r++ {
+		if r == 3 || r == 6 {
+			sb.WriteString("------+-------+------\n")
+		}
+		for c := 0; c < 9; c++ {
+			if c == 3 || c == 6 {
+				sb.WriteString("| ")
+			}
+			sb.WriteByte(cellChar(b.Values[r][c]))
+			if c < 8 {
+				sb.WriteByte(' ')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// SDK formats p as a SadMan puzzle: #D and #C headers for the name and each
+// line of the notes, then nine compact rows.
+func SDK(p *domain.Puzzle) string {
+	var sb strings.Builder
+	if p.Name != "" {
+		sb.WriteString("#D " + p.Name + "\n")
+	}
+	for _, n := range strings.Split(p.Notes, "\n") {
+		if n = strings.TrimSpace(n); n != "" {
+			sb.WriteString("#C " + n + "\n")
+		}
+	}
+	line := Line(&p.Board)
+	for r := 0; r < 9; r++ {
+		sb.WriteString(line[r*9:r*9+9] + "\n")
+	}
+	return sb.String()
+}
+
+// SS formats b in Simple Sudoku's boxed layout.
+func SS(b *domain.Board) string {
+	line := Line(b)
+	var sb strings.Builder
+	sb.WriteString("*-----------*\n")
+	for r := 0; r < 9; r++ {
+		if r == 3 || r == 6 {
+			sb.WriteString("|---+---+---|\n")
+		}
+		row := line[r*9 : r*9+9]
+		fmt.Fprintf(&sb, "|%s|%s|%s|\n", row[:3], row[3:6], row[6:])
+	}
+	sb.WriteString("*-----------*\n")
+	return sb.String()
+}
+
+// PM formats b as a HoDoKu pencil-mark grid. Empty cells list their
+// candidates from marks, or the ones the placed values leave when marks is
+// nil or has none for the cell. A lone candidate is written as [5] and a
+// cell with none left as ., so neither reads back as a placed value.
+func PM(b *domain.Board, marks *domain.Marks) string {
+	g, _ := domain.NewGrid(b)
+	if marks != nil {
+		g, _ = domain.GridFromMarks(b, marks)
+	}
+	var texts [81]string
+	var width [9]int
+	for cell := 0; cell < 81; cell++ {
+		if v := g.Values[cell]; v != 0 {
+			texts[cell] = string(cellChar(v))
+		} else {
+			switch cands := g.Cands[cell]; cands.Count() {
+			case 0:
+				texts[cell] = "."
+			case 1:
+				texts[cell] = "[" + string(cellChar(cands.List()[0])) + "]"
+			default:
+				for _, v := range cands.List() {
+					texts[cell] += string(cellChar(v))
+				}
+			}
+		}
+		width[cell%9] = max(width[cell%9], len(texts[cell]))
+	}
+	border := func(left, mid, right string) string {
+		var sb strings.Builder
+		for box := 0; box < 3; box++ {
+			sb.WriteString([]string{left, mid, mid}[box])
+			n := 2 + 2*2
+			for c := box * 3; c < box*3+3; c++ {
+				n += width[c]
+			}
+			sb.WriteString(strings.Repeat("-", n))
+		}
+		return sb.String() + right + "\n"
+	}
+	var sb strings.Builder
+	sb.WriteString(border(".", ".", "."))
+	for r := 0; r < 9; r++ {
+		if r == 3 || r == 6 {
+			sb.WriteString(border(":", "+", ":"))
+		}
+		for c := 0; c < 9; c++ {
+			switch c % 3 {
+			case 0:
+				sb.WriteString("| ")
+			default:
+				sb.WriteString("  ")
+			}
+			fmt.Fprintf(&sb, "%-*s", width[c], texts[r*9+c])
+			if c%3 == 2 {
+				sb.WriteByte(' ')
+			}
+		}
+		sb.WriteString("|\n")
+	}
+	sb.WriteString(border("'", "'", "'"))
+	return sb.String()
+}
+
+func cellChar(v uint8) byte {
+	if v == 0 {
+		return '.'
+	}
+	return '0' + v
+}
@@ -0,0 +1,197 @@
+package domain
+
+import "math/bits"
+
+// Digits is a set of digits 1..9; bit v set means digit v is included.
+type Digits uint16
+
+// AllDigits holds every digit 1..9.
+const AllDigits Digits = 0x3FE
+
+// DigitsOf returns the set holding vs.
+func DigitsOf(vs ...uint8) Digits {
+	var d Digits
+	for _, v := range vs {
+		d |= 1 << v
+	}
+	return d
+}
+
+func (d Digits) Has(v uint8) bool          { return d&(1<<v) != 0 }
+func (d Digits) With(v uint8) Digits       { return d | 1<<v }
+func (d Digits) Without(v uint8) Digits    { return d &^ (1 << v) }
+func (d Digits) Count() int                { return bits.OnesCount16(uint16(d)) }
+func (d Digits) Intersect(o Digits) Digits { return d & o }
+
+// First returns the lowest digit in the set, or 0 when it is empty.
+func (d Digits) First() uint8 {
+	if d == 0 {
+		return 0
+	}
+	return uint8(bits.TrailingZeros16(uint16(d)))
+}
+
+// Single returns the only digit of a one-element set.
+func (d Digits) Single() (uint8, bool) {
+	if d.Count() != 1 {
+		return 0, false
+	}
+	return d.First(), true
+}
+
+// List returns the digits in ascending order.
+func (d Digits) List() []uint8 {
+	out := make([]uint8, 0, d.Count())
+	for d != 0 {
+		v := uint8(bits.TrailingZeros16(uint16(d)))
+		out = append(out, v)
+		d &= d - 1
+	}
+	return out
+}
+
+// Cells are indexed 0..80 row-major; houses 0..26 are rows, columns, boxes.
+var (
+	Houses     [27][9]int // cells of each house
+	CellHouses [81][3]int // row, column and box house of each cell
+	Peers      [81][20]int
+)
+
+func init() {
+	for i := 0; i < 9; i++ {
+		for j := 0; j < 9; j++ {
+			Houses[i][j] = i*9 + j
+			Houses[9+i][j] = j*9 + i
+			Houses[18+i][j] = ((i/3)*3+j/3)*9 + (i%3)*3 + j%3
+		}
+	}
+	for cell := 0; cell < 81; cell++ {
+		r, c := cell/9, cell%9
+		CellHouses[cell] = [3]int{r, 9 + c, 18 + (r/3)*3 + c/3}
+		n := 0
+		for p := 0; p < 81; p++ {
+			pr, pc := p/9, p%9
+			if p != cell && (pr == r || pc == c || (pr/3 == r/3 && pc/3 == c/3)) {
+				Peers[cell][n] = p
+				n++
+			}
+		}
+	}
+}
+
+// CellIndex returns the 0..80 index of (r,c).
+func CellIndex(r, c int) int { return r*9 + c }
+
+// Coord converts a cell index back to a coordinate.
+func Coord(cell int) CellCoord { return CellCoord{Row: cell / 9, Col: cell % 9} }
+
+// HouseAt describes house index 0..26.
+func HouseAt(h int) House {
+	switch {
+	case h < 9:
+		return House{Kind: HouseRow, Index: h}
+	case h < 18:
+		return House{Kind: HouseCol, Index: h - 9}
+	default:
+		return House{Kind: HouseBox, Index: h - 18}
+	}
+}
+
+// Grid is the candidate state of a board: placed values and, for each empty
+// cell, the digits still possible there. It is a value type, so copying it
+// snapshots the state for backtracking.
+type Grid struct {
+	Values [81]uint8
+	Cands  [81]Digits
+}
+
+// NewGrid places the values of b and derives the candidates of the empty
+// cells. ok is false when two placed values conflict.
+func NewGrid(b *Board) (g Grid, ok bool) {
+	for i := range g.Cands {
+		g.Cands[i] = AllDigits
+	}
+	ok = true
+	for cell := 0; cell < 81; cell++ {
+		if v := b.Values[cell/9][cell%9]; v != 0 {
+			if v > 9 || !g.Cands[cell].Has(v) {
+				ok = false
+			}
+			g.Place(cell, v)
+		}
+	}
+	return g, ok
+}
+
+// GridFromMarks is like NewGrid but takes the candidates of annotated empty
+// cells from marks; cells without any mark keep the computed candidates.
+func GridFromMarks(b *Board, m *Marks) (Grid, bool) {
+	g, ok := NewGrid(b)
+	for cell := 0; cell < 81; cell++ {
+		r, c := cell/9, cell%9
+		if g.Values[cell] == 0 && m[r][c] != 0 {
+			g.Cands[cell] = m[r][c] & AllDigits
+		}
+	}
+	return g, ok
+}
+
+// Place sets cell to v and removes v from the candidates of its peers. It
+// reports whether every peer still has a candidate left.
+func (g *Grid) Place(cell int, v uint8) bool {
+	g.Values[cell] = v
+	g.Cands[cell] = 0
+	ok := true
+	for _, p := range Peers[cell] {
+		if g.Values[p] == 0 {
+			g.Cands[p] = g.Cands[p].Without(v)
+			ok = ok && g.Cands[p] != 0
+		}
+	}
+	return ok
+}
+
+// Eliminate removes v from the candidates of cell and reports whether it was there.
+func (g *Grid) Eliminate(cell int, v uint8) bool {
+	if !g.Cands[cell].Has(v) {
+		return false
+	}
+	g.Cands[cell] = g.Cands[cell].Without(v)
+	return true
+}
+
+// Allowed reports whether no peer of cell holds v, ignoring eliminations.
+func (g *Grid) Allowed(cell int, v uint8) bool {
+	for _, p := range Peers[cell] {
+		if g.Values[p] == v {
+			return false
+		}
+	}
+	return true
+}
+
+// Board returns the placed values as a board.
+func (g *Grid) Board() Board {
+	var b Board
+	for cell, v := range g.Values {
+		b.Values[cell/9][cell%9] = v
+	}
+	return b
+}
+
+// BestCell returns the empty cell with the fewest candidates (-1 when full).
+func (g *Grid) BestCell() int {
+	best, bestN := -1, 10
+	for cell := 0; cell < 81; cell++ {
+		if g.Values[cell] != 0 {
+			continue
+		}
+		if n := g.Cands[cell].Count(); n < bestN {
+			best, bestN = cell, n
+			if n <= 1 {
+				break
+			}
+		}
+	}
+	return best
+}
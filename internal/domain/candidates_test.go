@@ -0,0 +1,56 @@
+package domain
+
+import "testing"
+
+func TestHouseAndPeerTables(t *testing.T) {
+	for cell := 0; cell < 81; cell++ {
+		seen := map[int]bool{}
+		for _, p := range Peers[cell] {
+			if p == cell || seen[p] {
+				t.Fatalf("cell %d: bad peer %d", cell, p)
+			}
+			seen[p] = true
+		}
+		for _, h := range CellHouses[cell] {
+			found := false
+			for _, c := range Houses[h] {
+				found = found || c == cell
+			}
+			if !found {
+				t.Fatalf("cell %d not in its house %v", cell, HouseAt(h))
+			}
+		}
+	}
+	if got := HouseAt(22); got != (House{Kind: HouseBox, Index: 4}) || Houses[22][0] != CellIndex(3, 3) {
+		t.Fatalf("box 5 = %v starting at %d", got, Houses[22][0])
+	}
+}
+
+func TestGridPlaceEliminate(t *testing.T) {
+	var b Board
+	b.Values[0][0] = 5
+	g, ok := NewGrid(&b)
+	if !ok {
+		t.Fatalf("NewGrid reported a conflict")
+	}
+	if g.Cands[CellIndex(0, 8)].Has(5) || g.Cands[CellIndex(2, 2)].Has(5) || !g.Cands[CellIndex(4, 4)].Has(5) {
+		t.Fatalf("placing 5 at r1c1 did not update peers correctly")
+	}
+	if !g.Eliminate(CellIndex(4, 4), 5) || g.Eliminate(CellIndex(4, 4), 5) {
+		t.Fatalf("Eliminate should remove once")
+	}
+	if !g.Allowed(CellIndex(4, 4), 5) {
+		t.Fatalf("Allowed must ignore eliminations")
+	}
+	if d := DigitsOf(2, 7); d.Count() != 2 || d.First() != 2 || len(d.List()) != 2 {
+		t.Fatalf("DigitsOf(2,7) = %b", d)
+	}
+	if v, ok := DigitsOf(9).Single(); !ok || v != 9 {
+		t.Fatalf("Single = %d,%v", v, ok)
+	}
+
+	b.Values[0][8] = 5
+	if _, ok := NewGrid(&b); ok {
+		t.Fatalf("duplicate 5 in row 1 should be reported")
+	}
+}
@@ -1,5 +1,7 @@
 package domain
 
+import "strings"
+
 // Difficulty labels target puzzle generation & grading.
 type Difficulty int
 
@@ -10,6 +12,20 @@ const (
 	Expert
 )
 
+// String returns the lowercase label used in URLs, file paths and the UI.
+func (d Difficulty) String() string {
+	switch d {
+	case Easy:
+		return "easy"
+	case Hard:
+		return "hard"
+	case Expert:
+		return "expert"
+	default:
+		return "medium"
+	}
+}
+
 // StrategyTier limits hinting/logic complexity used.
 type StrategyTier int
 
@@ -17,5 +33,46 @@ const (
 	StrategySingles StrategyTier = iota // singles / sole candidates
 	StrategyPairs                       // naked/hidden pairs
 	StrategyAdvanced                    // pointing/claiming, triples, etc.
-	StrategyXWing                       // advanced fish (placeholder for cap)
-)
\ No newline at end of file
+	StrategyXWing                       // X-Wing, Swordfish, Jellyfish
+	StrategyChains                      // wings, coloring, X- and XY-chains
+	StrategyFinnedFish                  // finned and sashimi fish
+	StrategyUniqueness                  // unique rectangles, BUG+1; needs a unique puzzle
+	StrategyLastResort                  // forcing chains or a revealed digit
+)
+
+// String returns the lowercase tier name that ParseStrategyTier reads back,
+// as the API's maxTier and the CLI's --max-tier do.
+func (t StrategyTier) String() string {
+	switch t {
+	case StrategySingles:
+		return "singles"
+	case StrategyPairs:
+		return "pairs"
+	case StrategyAdvanced:
+		return "advanced"
+	case StrategyXWing:
+		return "xwing"
+	case StrategyChains:
+		return "chains"
+	case StrategyFinnedFish:
+		return "finned"
+	case StrategyUniqueness:
+		return "uniqueness"
+	case StrategyLastResort:
+		return "lastresort"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseStrategyTier accepts a tier name as written by String, ignoring case
+// and surrounding space.
+func ParseStrategyTier(s string) (StrategyTier, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for t := StrategySingles; t <= StrategyLastResort; t++ {
+		if s == t.String() {
+			return t, true
+		}
+	}
+	return 0, false
+}
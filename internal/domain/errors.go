@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// ErrorKind classifies a failure so callers can react to it, for example
+// with an HTTP status or an exit code, without matching messages. The
+// values are stable and appear in API responses.
+type ErrorKind string
+
+const (
+	KindInternal       ErrorKind = "internal"        // anything not classified below
+	KindInvalid        ErrorKind = "invalid_input"   // malformed or inconsistent input, like conflicting givens
+	KindUnsolvable     ErrorKind = "unsolvable"      // the givens admit no solution
+	KindNotUnique      ErrorKind = "not_unique"      // the givens admit more than one solution
+	KindNotFound       ErrorKind = "not_found"       // no puzzle, game or daily by that name
+	KindConflict       ErrorKind = "conflict"        // the request clashes with the stored state
+	KindTimeout        ErrorKind = "timeout"         // the deadline passed before an answer was found
+	KindCanceled       ErrorKind = "canceled"        // the caller gave up
+	KindNotImplemented ErrorKind = "not_implemented" // the service was set up without what it needs
+)
+
+// Error is a failure of a known kind. Details carry structured context for
+// clients, like the conflicting cells; Err is the underlying cause, if any.
+type Error struct {
+	Kind    ErrorKind
+	Message string
+	Details map[string]any
+	Err     error
+}
+
+// Sentinels to test the kind of an error with errors.Is.
+var (
+	ErrInvalid        = &Error{Kind: KindInvalid}
+	ErrUnsolvable     = &Error{Kind: KindUnsolvable}
+	ErrNotUnique      = &Error{Kind: KindNotUnique}
+	ErrNotFound       = &Error{Kind: KindNotFound}
+	ErrConflict       = &Error{Kind: KindConflict}
+	ErrNotImplemented = &Error{Kind: KindNotImplemented}
+)
+
+// Errorf returns an Error of kind with a formatted message. As with
+// fmt.Errorf, a %w verb makes the operand its cause.
+func Errorf(kind ErrorKind, format string, args ...any) *Error {
+	err := fmt.Errorf(format, args...)
+	return &Error{Kind: kind, Message: err.Error(), Err: errors.Unwrap(err)}
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return string(e.Kind)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Is reports whether target is the sentinel of e's kind.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Message == "" && t.Details == nil && t.Err == nil && t.Kind == e.Kind
+}
+
+// KindOf classifies err: the kind of the first Error in its chain, else
+// timeout or canceled for context errors, not found for fs.ErrNotExist and
+// internal for the rest. A nil error has no kind.
+func KindOf(err error) ErrorKind {
+	var e *Error
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &e):
+		return e.Kind
+	case errors.Is(err, context.DeadlineExceeded):
+		return KindTimeout
+	case errors.Is(err, context.Canceled):
+		return KindCanceled
+	case errors.Is(err, fs.ErrNotExist):
+		return KindNotFound
+	default:
+		return KindInternal
+	}
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestErrorKinds(t *testing.T) {
+	cause := errors.New("disk on fire")
+	wrapped := fmt.Errorf("saving: %w", Errorf(KindConflict, "puzzle %s: %w", "p1", cause))
+	cases := []struct {
+		err  error
+		want ErrorKind
+	}{
+		{nil, ""},
+		{cause, KindInternal},
+		{wrapped, KindConflict},
+		{ErrFixedCell, KindInvalid},
+		{fmt.Errorf("solve: %w", context.DeadlineExceeded), KindTimeout},
+		{context.Canceled, KindCanceled},
+		{&os.PathError{Op: "open", Path: "x", Err: os.ErrNotExist}, KindNotFound},
+	}
+	for _, c := range cases {
+		if got := KindOf(c.err); got != c.want {
+			t.Errorf("KindOf(%v) = %q, want %q", c.err, got, c.want)
+		}
+	}
+	if !errors.Is(wrapped, ErrConflict) || errors.Is(wrapped, ErrNotFound) || !errors.Is(wrapped, cause) {
+		t.Errorf("errors.Is on %v does not match its kind and cause", wrapped)
+	}
+	if errors.Is(ErrNothingUndo, ErrNothingRedo) {
+		t.Errorf("distinct errors of one kind should not match each other")
+	}
+	if got := wrapped.Error(); got != "saving: puzzle p1: disk on fire" {
+		t.Errorf("message = %q", got)
+	}
+}
@@ -0,0 +1,147 @@
+package domain
+
+// MoveKind enumerates the entries of a game's move log.
+type MoveKind string
+
+const (
+	MoveSet   MoveKind = "set"   // place a digit
+	MoveClear MoveKind = "clear" // empty a cell
+	MoveNote  MoveKind = "note"  // toggle a pencil mark
+	MoveUndo  MoveKind = "undo"  // revert the latest move in effect
+	MoveRedo  MoveKind = "redo"  // re-apply the latest undone move
+)
+
+// Move is one timestamped entry in a game's append-only log.
+type Move struct {
+	Kind  MoveKind `json:"kind"`
+	Row   int      `json:"row"`
+	Col   int      `json:"col"`
+	Value uint8    `json:"value,omitempty"`
+	At    int64    `json:"at"` // unix nanos
+}
+
+// Marks holds per-cell pencil marks as digit sets.
+type Marks [9][9]Digits
+
+// Has reports whether digit v is marked at (r,c).
+func (m *Marks) Has(r, c int, v uint8) bool { return m[r][c].Has(v) }
+
+// Toggle flips the mark for digit v at (r,c).
+func (m *Marks) Toggle(r, c int, v uint8) { m[r][c] ^= 1 << v }
+
+var (
+	ErrFixedCell   = Errorf(KindInvalid, "cell is a given")
+	ErrNothingUndo = Errorf(KindConflict, "nothing to undo")
+	ErrNothingRedo = Errorf(KindConflict, "nothing to redo")
+)
+
+// Game is a play session on a stored puzzle. Moves keeps the full log,
+// including undo/redo entries; Board and Marks are the state it replays to.
+type Game struct {
+	ID        string `json:"id"`
+	PuzzleID  string `json:"puzzleId"`
+	Start     Board  `json:"start"`
+	Board     Board  `json:"board"`
+	Marks     Marks  `json:"marks"`
+	Moves     []Move `json:"moves,omitempty"`
+	HintsUsed int    `json:"hintsUsed,omitempty"`
+	CreatedAt int64  `json:"createdAt"`
+	UpdatedAt int64  `json:"updatedAt"`
+}
+
+// NewGame starts a session on p. Cells without an explicit fixed flag are
+// treated as givens when they hold a value, matching older saves.
+func NewGame(id string, p *Puzzle, now int64) *Game {
+	start := p.Board
+	anyFixed := false
+	for r := 0; r < 9; r++ {
+		for c := 0; c < 9; c++ {
+			anyFixed = anyFixed || start.Fixed[r][c]
+		}
+	}
+	if !anyFixed {
+		for r := 0; r < 9; r++ {
+			for c := 0; c < 9; c++ {
+				start.Fixed[r][c] = start.Values[r][c] != 0
+			}
+		}
+	}
+	return &Game{ID: id, PuzzleID: p.ID, Start: start, Board: start, CreatedAt: now, UpdatedAt: now}
+}
+
+// Play validates m, appends it to the log and rebuilds the current state.
+func (g *Game) Play(m Move) error {
+	applied, redo := g.History()
+	switch m.Kind {
+	case MoveSet, MoveClear, MoveNote:
+		if m.Row < 0 || m.Row > 8 || m.Col < 0 || m.Col > 8 {
+			return Errorf(KindInvalid, "cell out of range: r%dc%d", m.Row+1, m.Col+1)
+		}
+		if g.Start.Fixed[m.Row][m.Col] {
+			return ErrFixedCell
+		}
+		if m.Kind == MoveClear {
+			m.Value = 0
+		} else if m.Value < 1 || m.Value > 9 {
+			return Errorf(KindInvalid, "invalid digit %d", m.Value)
+		}
+	case MoveUndo:
+		if len(applied) == 0 {
+			return ErrNothingUndo
+		}
+		m.Row, m.Col, m.Value = 0, 0, 0
+	case MoveRedo:
+		if len(redo) == 0 {
+			return ErrNothingRedo
+		}
+		m.Row, m.Col, m.Value = 0, 0, 0
+	default:
+		return Errorf(KindInvalid, "unknown move kind %q", m.Kind)
+	}
+	g.Moves = append(g.Moves, m)
+	g.UpdatedAt = m.At
+	g.Rebuild()
+	return nil
+}
+
+// History replays the log and returns the indices of the moves currently in
+// effect (oldest first) and of those available for redo (next redo last).
+func (g *Game) History() (applied, redo []int) {
+	for i, m := range g.Moves {
+		switch m.Kind {
+		case MoveUndo:
+			if n := len(applied); n > 0 {
+				redo = append(redo, applied[n-1])
+				applied = applied[:n-1]
+			}
+		case MoveRedo:
+			if n := len(redo); n > 0 {
+				applied = append(applied, redo[n-1])
+				redo = redo[:n-1]
+			}
+		default:
+			applied = append(applied, i)
+			redo = redo[:0]
+		}
+	}
+	return applied, redo
+}
+
+// Rebuild recomputes Board and Marks from Start and the moves in effect.
+func (g *Game) Rebuild() {
+	applied, _ := g.History()
+	g.Board = g.Start
+	g.Marks = Marks{}
+	for _, i := range applied {
+		m := g.Moves[i]
+		switch m.Kind {
+		case MoveSet:
+			g.Board.Values[m.Row][m.Col] = m.Value
+			g.Marks[m.Row][m.Col] = 0
+		case MoveClear:
+			g.Board.Values[m.Row][m.Col] = 0
+		case MoveNote:
+			g.Marks.Toggle(m.Row, m.Col, m.Value)
+		}
+	}
+}
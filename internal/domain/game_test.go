@@ -0,0 +1,49 @@
+package domain
+
+import "testing"
+
+func TestGameUndoRedo(t *testing.T) {
+	p := &Puzzle{ID: "p1"}
+	p.Board.Values[0][0] = 5
+	g := NewGame("g1", p, 1)
+	if !g.Start.Fixed[0][0] {
+		t.Fatalf("given without fixed flag should be treated as fixed")
+	}
+
+	play := func(m Move) {
+		t.Helper()
+		if err := g.Play(m); err != nil {
+			t.Fatalf("Play(%+v): %v", m, err)
+		}
+	}
+	if err := g.Play(Move{Kind: MoveSet, Row: 0, Col: 0, Value: 1}); err != ErrFixedCell {
+		t.Fatalf("expected ErrFixedCell, got %v", err)
+	}
+	play(Move{Kind: MoveNote, Row: 0, Col: 1, Value: 3, At: 2})
+	play(Move{Kind: MoveSet, Row: 0, Col: 1, Value: 7, At: 3})
+	if g.Board.Values[0][1] != 7 || g.Marks[0][1] != 0 {
+		t.Fatalf("set should place digit and clear marks: %v %b", g.Board.Values[0][1], g.Marks[0][1])
+	}
+
+	play(Move{Kind: MoveUndo, At: 4})
+	if g.Board.Values[0][1] != 0 || !g.Marks.Has(0, 1, 3) {
+		t.Fatalf("undo should restore note state")
+	}
+	play(Move{Kind: MoveRedo, At: 5})
+	if g.Board.Values[0][1] != 7 {
+		t.Fatalf("redo should re-apply set")
+	}
+
+	play(Move{Kind: MoveUndo, At: 6})
+	play(Move{Kind: MoveClear, Row: 0, Col: 2, At: 7})
+	if err := g.Play(Move{Kind: MoveRedo}); err != ErrNothingRedo {
+		t.Fatalf("a new move should drop the redo stack, got %v", err)
+	}
+	applied, _ := g.History()
+	if len(applied) != 2 || len(g.Moves) != 6 {
+		t.Fatalf("applied=%v moves=%d", applied, len(g.Moves))
+	}
+	if g.UpdatedAt != 7 {
+		t.Fatalf("UpdatedAt = %d", g.UpdatedAt)
+	}
+}
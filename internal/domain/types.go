@@ -1,5 +1,7 @@
 package domain
 
+import "fmt"
+
 // Board holds current values and which cells are fixed givens.
 type Board struct {
 	Values [9][9]uint8 `json:"board"`
@@ -14,9 +16,82 @@ type CellCoord struct {
 
 // Hint describes a strategy suggestion for the UI.
 type Hint struct {
-	Message  string       `json:"message,omitempty"`
-	Cells    []CellCoord  `json:"cells,omitempty"`
-	Strategy StrategyTier `json:"strategy,omitempty"`
+	Message   string       `json:"message,omitempty"`
+	Cells     []CellCoord  `json:"cells,omitempty"`
+	Strategy  StrategyTier `json:"strategy,omitempty"`
+	Technique string       `json:"technique,omitempty"` // e.g. "hidden single"
+	House     *House       `json:"house,omitempty"`     // house the step is found in
+	Digit     uint8        `json:"digit,omitempty"`     // digit placed by the step
+	// Eliminations are candidates the step removes.
+	Eliminations []Candidate `json:"eliminations,omitempty"`
+	// Links is the chain behind the step, in order, for drawing arrows.
+	Links []Link    `json:"links,omitempty"`
+	Fish  *Fish     `json:"fish,omitempty"`  // base/cover sets of fish steps
+	Level HintLevel `json:"level,omitempty"` // disclosure level; 0 = everything
+}
+
+// Fish describes a fish pattern: within the base houses the digit only
+// fits in the cover houses, apart from the fins.
+type Fish struct {
+	Base  []House     `json:"base"`
+	Cover []House     `json:"cover"`
+	Fins  []CellCoord `json:"fins,omitempty"`
+}
+
+// LinkKind tells how two candidates of a chain are related.
+type LinkKind string
+
+const (
+	LinkStrong LinkKind = "strong" // at least one end is true
+	LinkWeak   LinkKind = "weak"   // at most one end is true
+)
+
+// Link connects two candidates of a chain.
+type Link struct {
+	From Candidate `json:"from"`
+	To   Candidate `json:"to"`
+	Kind LinkKind  `json:"kind"`
+}
+
+// HintLevel controls how much of a hint is disclosed.
+type HintLevel int
+
+const (
+	HintNudge  HintLevel = iota + 1 // name the technique
+	HintHouse                       // ...and the house
+	HintCells                       // ...and highlight the cells
+	HintAnswer                      // ...and give the digit
+)
+
+// Disclose returns the hint reduced to what level reveals. Levels outside
+// 1..4 return the full hint.
+func (h Hint) Disclose(level HintLevel) Hint {
+	if level < HintNudge || level >= HintAnswer {
+		h.Level = HintAnswer
+		return h
+	}
+	out := Hint{Strategy: h.Strategy, Technique: h.Technique, Level: level}
+	switch level {
+	case HintNudge:
+		out.Message = fmt.Sprintf("Look for a %s.", h.Technique)
+	case HintHouse:
+		out.House = h.House
+		if h.House != nil {
+			out.Message = fmt.Sprintf("Look for a %s in %s.", h.Technique, h.House)
+		} else {
+			out.Message = fmt.Sprintf("Look for a %s.", h.Technique)
+		}
+	case HintCells:
+		out.House = h.House
+		out.Cells = h.Cells
+		out.Message = fmt.Sprintf("Look at the highlighted cells for a %s.", h.Technique)
+	}
+	return out
+}
+
+// Key identifies the underlying step so repeated requests can escalate.
+func (h Hint) Key() string {
+	return fmt.Sprintf("%s|%v|%d|%v", h.Technique, h.Cells, h.Digit, h.Eliminations)
 }
 
 // Puzzle is a persisted Sudoku with metadata.
@@ -27,8 +102,41 @@ type Puzzle struct {
 	Board      Board      `json:"board"`
 	CreatedAt  int64      `json:"createdAt,omitempty"`
 	// Optional user metadata
-	Name  string `json:"name,omitempty"`
-	Notes string `json:"notes,omitempty"`
+	Name       string   `json:"name,omitempty"`
+	Notes      string   `json:"notes,omitempty"`
+	Collection string   `json:"collection,omitempty"` // set by bulk imports
+	Tags       []string `json:"tags,omitempty"`
+	Favorite   bool     `json:"favorite,omitempty"`
+	// Play state restored on load
+	Marks        *Marks `json:"marks,omitempty"` // pencil marks; nil when none were taken
+	ElapsedNanos int64  `json:"elapsedNanos,omitempty"`
+	Mistakes     int    `json:"mistakes,omitempty"`
+	Completed    bool   `json:"completed,omitempty"`
+}
+
+// Givens returns the fixed cells of the board, or every placed value when
+// none are marked fixed, as older saves and plain-text imports do.
+func (p *Puzzle) Givens() Board {
+	var g Board
+	fixed := false
+	for r := 0; r < 9; r++ {
+		for c := 0; c < 9; c++ {
+			if p.Board.Fixed[r][c] {
+				g.Values[r][c] = p.Board.Values[r][c]
+				g.Fixed[r][c] = true
+				fixed = true
+			}
+		}
+	}
+	if !fixed {
+		g.Values = p.Board.Values
+		for r := 0; r < 9; r++ {
+			for c := 0; c < 9; c++ {
+				g.Fixed[r][c] = g.Values[r][c] != 0
+			}
+		}
+	}
+	return g
 }
 
 // PuzzleMeta is a lightweight listing entry.
@@ -37,4 +145,98 @@ type PuzzleMeta struct {
 	Name       string     `json:"name,omitempty"`
 	Difficulty Difficulty `json:"difficulty"`
 	CreatedAt  int64      `json:"createdAt"`
-}
\ No newline at end of file
+	Completed  bool       `json:"completed,omitempty"`
+	Collection string     `json:"collection,omitempty"`
+	Tags       []string   `json:"tags,omitempty"`
+	Favorite   bool       `json:"favorite,omitempty"`
+}
+
+// PuzzlePatch changes the user metadata of a stored puzzle. Nil fields are
+// left as they are; an empty Tags list removes all tags.
+type PuzzlePatch struct {
+	Name     *string   `json:"name,omitempty"`
+	Notes    *string   `json:"notes,omitempty"`
+	Tags     *[]string `json:"tags,omitempty"`
+	Favorite *bool     `json:"favorite,omitempty"`
+}
+
+// Apply sets the fields of p that pp changes.
+func (pp *PuzzlePatch) Apply(p *Puzzle) {
+	if pp.Name != nil {
+		p.Name = *pp.Name
+	}
+	if pp.Notes != nil {
+		p.Notes = *pp.Notes
+	}
+	if pp.Tags != nil {
+		p.Tags = *pp.Tags
+	}
+	if pp.Favorite != nil {
+		p.Favorite = *pp.Favorite
+	}
+}
+
+// Check is the outcome of comparing a board with the unique solution of its givens.
+type Check struct {
+	Conflicts []CellCoord `json:"conflicts,omitempty"` // duplicates in a row/col/box
+	Wrong     []CellCoord `json:"wrong,omitempty"`     // entries that differ from the solution
+	Solvable  bool        `json:"solvable"`            // current entries can still be completed
+}
+
+// HouseKind names the three unit types of the grid.
+type HouseKind string
+
+const (
+	HouseRow HouseKind = "row"
+	HouseCol HouseKind = "col"
+	HouseBox HouseKind = "box"
+)
+
+// House identifies a row, column or 3×3 box; Index is 0..8 (boxes row-major).
+type House struct {
+	Kind  HouseKind `json:"kind"`
+	Index int       `json:"index"`
+}
+
+func (h House) String() string {
+	switch h.Kind {
+	case HouseRow:
+		return fmt.Sprintf("row %d", h.Index+1)
+	case HouseCol:
+		return fmt.Sprintf("column %d", h.Index+1)
+	default:
+		return fmt.Sprintf("box %d", h.Index+1)
+	}
+}
+
+// ContradictionKind classifies why a board cannot be completed.
+type ContradictionKind string
+
+const (
+	NoCandidates ContradictionKind = "no-candidates" // empty cell with no digit left
+	NoPlace      ContradictionKind = "no-place"      // digit with no cell left in a house
+	SameSingle   ContradictionKind = "same-single"   // two cells in a house forced to one digit
+)
+
+// Contradiction is a structural dead end found without searching.
+type Contradiction struct {
+	Kind    ContradictionKind `json:"kind"`
+	Cells   []CellCoord       `json:"cells,omitempty"`
+	House   *House            `json:"house,omitempty"`
+	Digit   uint8             `json:"digit,omitempty"`
+	Message string            `json:"message"`
+}
+
+// Candidate is a digit at a cell, e.g. a pencil mark or an elimination.
+type Candidate struct {
+	Row   int   `json:"row"`
+	Col   int   `json:"col"`
+	Digit uint8 `json:"digit"`
+}
+
+// MarksReport lists pencil-mark mistakes: the solution digit missing from a
+// cell's notes, or a noted digit already ruled out by a placed peer.
+type MarksReport struct {
+	Missing    []Candidate `json:"missing,omitempty"`
+	Impossible []Candidate `json:"impossible,omitempty"`
+}
@@ -0,0 +1,99 @@
+package generator
+
+import (
+	"context"
+
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/ports"
+)
+
+// Rating summarises how a puzzle solves with logic alone.
+type Rating struct {
+	Tier       domain.StrategyTier `json:"tier"`   // hardest tier needed
+	Steps      int                 `json:"steps"`  // hints applied
+	Solved     bool                `json:"solved"` // false when the hinter got stuck
+	Techniques map[string]int      `json:"techniques,omitempty"`
+}
+
+// Rate solves b the way a player would: it asks h for the next step on the
+// current candidates, applies it, and repeats until the board is full or no
+// step within max is found. Placements and eliminations are both applied, so
+// any strategy the hinter offers counts towards the rating.
+func Rate(ctx context.Context, h ports.Hinter, b *domain.Board, max domain.StrategyTier) (Rating, error) {
+	board := domain.Board{Values: b.Values}
+	g, _ := domain.NewGrid(&board)
+	var marks domain.Marks
+	for cell, m := range g.Cands {
+		marks[cell/9][cell%9] = m
+	}
+	rt := Rating{Techniques: map[string]int{}}
+	for {
+		if isFull(&board) {
+			rt.Solved = true
+			return rt, nil
+		}
+		hh, ok, err := h.HintFrom(ctx, &board, &marks, max)
+		if err != nil {
+			return rt, err
+		}
+		if !ok || !apply(&board, &marks, hh) {
+			return rt, nil
+		}
+		rt.Steps++
+		rt.Techniques[hh.Technique]++
+		if hh.Strategy > rt.Tier {
+			rt.Tier = hh.Strategy
+		}
+	}
+}
+
+// Difficulty maps the rating onto the generator's labels: singles are easy,
+// pairs and intersections medium, fish and chains hard, and anything harder,
+// or a puzzle logic alone does not finish, expert.
+func (rt Rating) Difficulty() domain.Difficulty {
+	switch {
+	case !rt.Solved:
+		return domain.Expert
+	case rt.Tier == domain.StrategySingles:
+		return domain.Easy
+	case rt.Tier <= domain.StrategyAdvanced:
+		return domain.Medium
+	case rt.Tier <= domain.StrategyChains:
+		return domain.Hard
+	}
+	return domain.Expert
+}
+
+// apply plays hh on board and marks and reports whether anything changed.
+func apply(board *domain.Board, marks *domain.Marks, hh domain.Hint) bool {
+	changed := false
+	if hh.Digit != 0 && len(hh.Cells) == 1 {
+		r, c := hh.Cells[0].Row, hh.Cells[0].Col
+		if board.Values[r][c] == 0 {
+			board.Values[r][c] = hh.Digit
+			marks[r][c] = 0
+			for _, p := range domain.Peers[domain.CellIndex(r, c)] {
+				marks[p/9][p%9] = marks[p/9][p%9].Without(hh.Digit)
+			}
+			changed = true
+		}
+	}
+	for _, e := range hh.Eliminations {
+		if marks.Has(e.Row, e.Col, e.Digit) {
+			marks[e.Row][e.Col] = marks[e.Row][e.Col].Without(e.Digit)
+			changed = true
+		}
+	}
+	return changed
+}
+
+func isFull(b *domain.Board) bool {
+	for r := 0; r < 9; r++ {
+		for c := 0; c < 9; c++ {
+			if b.Values[r][c] == 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
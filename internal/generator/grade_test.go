@@ -0,0 +1,30 @@
+package generator
+
+import (
+	"context"
+	"testing"
+
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/hint"
+)
+
+func TestRateSinglesPuzzle(t *testing.T) {
+	b := &domain.Board{Values: [9][9]uint8{
+		{5, 3, 0, 0, 7, 0, 0, 0, 0},
+		{6, 0, 0, 1, 9, 5, 0, 0, 0},
+		{0, 9, 8, 0, 0, 0, 0, 6, 0},
+		{8, 0, 0, 0, 6, 0, 0, 0, 3},
+		{4, 0, 0, 8, 0, 3, 0, 0, 1},
+		{7, 0, 0, 0, 2, 0, 0, 0, 6},
+		{0, 6, 0, 0, 0, 0, 2, 8, 0},
+		{0, 0, 0, 4, 1, 9, 0, 0, 5},
+		{0, 0, 0, 0, 8, 0, 0, 7, 9},
+	}}
+	rt, err := Rate(context.Background(), hint.NewPipeline(), b, domain.StrategyChains)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rt.Solved || rt.Tier != domain.StrategySingles || rt.Steps != 51 {
+		t.Fatalf("rating = %+v", rt)
+	}
+}
@@ -88,41 +88,35 @@ func countGivens(b *[9][9]uint8) int {
 // fillRandom solves an empty grid into a full valid solution by random ordering.
 func fillRandom(ctx context.Context, rng *rand.Rand, grid *[9][9]uint8) bool {
 	var nums [9]uint8
-	for i := 0; i < 9; i++ { nums[i] = uint8(i + 1) }
-	var dfs func(int, int) bool
-	dfs = func(r, c int) bool {
-		if ctx.Err() != nil { return false }
-		if r == 9 { return true }
-		nr, nc := r, c+1
-		if nc == 9 { nr, nc = r+1, 0 }
-		// random order
-		rng.Shuffle(9, func(i, j int){ nums[i], nums[j] = nums[j], nums[i] })
-		for _, v := range nums {
-			if allowed(grid, r, c, v) {
-				grid[r][c] = v
-				if dfs(nr, nc) { return true }
-				grid[r][c] = 0
-			}
-		}
-		return false
-	}
-	return dfs(0,0)
-}
-
-// allowed mirrors row/col/box checks locally for the generator.
-func allowed(b *[9][9]uint8, r, c int, v uint8) bool {
 	for i := 0; i < 9; i++ {
-		if b[r][i] == v || b[i][c] == v {
+		nums[i] = uint8(i + 1)
+	}
+	var dfs func(g *domain.Grid, cell int) bool
+	dfs = func(g *domain.Grid, cell int) bool {
+		if ctx.Err() != nil {
 			return false
 		}
-	}
-	br, bc := (r/3)*3, (c/3)*3
-	for dr := 0; dr < 3; dr++ {
-		for dc := 0; dc < 3; dc++ {
-			if b[br+dr][bc+dc] == v {
-				return false
+		if cell == 81 {
+			*grid = g.Board().Values
+			return true
+		}
+		// random order
+		rng.Shuffle(9, func(i, j int) { nums[i], nums[j] = nums[j], nums[i] })
+		for _, v := range nums { // ranging over the array copies it, so recursion can reshuffle
+			if !g.Cands[cell].Has(v) {
+				continue
+			}
+			// keep descending even when a peer runs dry: the search has
+			// always reshuffled at every cell it reaches, and seeds must
+			// keep yielding the same grids
+			next := *g
+			next.Place(cell, v)
+			if dfs(&next, cell+1) {
+				return true
 			}
 		}
+		return false
 	}
-	return true
-}
\ No newline at end of file
+	empty, _ := domain.NewGrid(&domain.Board{})
+	return dfs(&empty, 0)
+}
@@ -2,6 +2,7 @@ package generator
 
 import (
 	"context"
+	"math/rand"
 	"testing"
 	"time"
 
@@ -55,4 +56,23 @@ func TestGenerateAllDifficultiesUnder1s(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+// TestFillRandomSeed pins the solution grid a seed yields, so shared seeds
+// and daily puzzles keep producing the same boards.
+func TestFillRandomSeed(t *testing.T) {
+	want := [9][9]uint8{
+		{3, 5, 7, 1, 8, 9, 4, 2, 6},
+		{4, 1, 2, 7, 5, 6, 3, 8, 9},
+		{6, 9, 8, 3, 4, 2, 1, 7, 5},
+		{8, 7, 9, 5, 3, 1, 2, 6, 4},
+		{1, 4, 3, 6, 2, 7, 9, 5, 8},
+		{5, 2, 6, 8, 9, 4, 7, 1, 3},
+		{9, 8, 1, 2, 6, 3, 5, 4, 7},
+		{7, 6, 4, 9, 1, 5, 8, 3, 2},
+		{2, 3, 5, 4, 7, 8, 6, 9, 1},
+	}
+	var got [9][9]uint8
+	if !fillRandom(context.Background(), rand.New(rand.NewSource(0)), &got) || got != want {
+		t.Fatalf("seed 0 filled %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,291 @@
+package hint
+
+import (
+	"fmt"
+	"strings"
+
+	"svw.info/sudoku/internal/domain"
+)
+
+// strongLinks returns, for each cell, the cells it forms a conjugate pair
+// with on digit v (the only two places for v in some house).
+func strongLinks(g *domain.Grid, v uint8) [81][]int {
+	var adj [81][]int
+	for h := range domain.Houses {
+		a, b, ok := conjugate(g, h, v)
+		if !ok || contains(adj[a], b) {
+			continue
+		}
+		adj[a] = append(adj[a], b)
+		adj[b] = append(adj[b], a)
+	}
+	return adj
+}
+
+func contains(cells []int, cell int) bool {
+	for _, c := range cells {
+		if c == cell {
+			return true
+		}
+	}
+	return false
+}
+
+// simpleColoring colors the conjugate pairs of a digit in two alternating
+// colors; exactly one color is true. Two cells of one color seeing each
+// other make that color false (wrap); a cell seeing both colors loses the
+// digit (trap).
+func simpleColoring(g *domain.Grid) (domain.Hint, bool) {
+	for v := uint8(1); v <= 9; v++ {
+		adj := strongLinks(g, v)
+		var color [81]int8 // 0 uncolored, 1 or 2
+		for start := 0; start < 81; start++ {
+			if len(adj[start]) == 0 || color[start] != 0 {
+				continue
+			}
+			comp := []int{start}
+			color[start] = 1
+			var links []domain.Link
+			for i := 0; i < len(comp); i++ {
+				c := comp[i]
+				for _, n := range adj[c] {
+					if color[n] == 0 {
+						color[n] = 3 - color[c]
+						comp = append(comp, n)
+					}
+					if c < n {
+						links = append(links, strong(c, v, n, v))
+					}
+				}
+			}
+			if len(links) < 2 {
+				continue
+			}
+			if hh, ok := colorWrap(g, v, comp, &color, links); ok {
+				return hh, true
+			}
+			var elims []domain.Candidate
+			for cell := 0; cell < 81; cell++ {
+				if color[cell] != 0 || !g.Cands[cell].Has(v) {
+					continue
+				}
+				var seen [3]bool
+				for _, c := range comp {
+					if sees(cell, c) {
+						seen[color[c]] = true
+					}
+				}
+				if seen[1] && seen[2] {
+					elims = append(elims, cand(cell, v))
+				}
+			}
+			if len(elims) > 0 {
+				return domain.Hint{
+					Message:      fmt.Sprintf("Simple coloring on %d: one color is true and these cells see both, so remove %s", v, elimText(elims)),
+					Cells:        coords(comp...),
+					Strategy:     domain.StrategyChains,
+					Technique:    "simple coloring",
+					Eliminations: elims,
+					Links:        links,
+				}, true
+			}
+		}
+	}
+	return domain.Hint{}, false
+}
+
+func colorWrap(g *domain.Grid, v uint8, comp []int, color *[81]int8, links []domain.Link) (domain.Hint, bool) {
+	for i, a := range comp {
+		for _, b := range comp[i+1:] {
+			if color[a] != color[b] || !sees(a, b) {
+				continue
+			}
+			var elims []domain.Candidate
+			for _, c := range comp {
+				if color[c] == color[a] {
+					elims = append(elims, cand(c, v))
+				}
+			}
+			return domain.Hint{
+				Message: fmt.Sprintf("Simple coloring on %d: %s and %s share a color and see each other, so that color is false; remove %s",
+					v, cellName(a), cellName(b), elimText(elims)),
+				Cells:        coords(comp...),
+				Strategy:     domain.StrategyChains,
+				Technique:    "simple coloring",
+				Eliminations: elims,
+				Links:        links,
+			}, true
+		}
+	}
+	return domain.Hint{}, false
+}
+
+// xChain finds a single-digit chain of alternating strong and weak links
+// that starts and ends with a strong link: one of its ends holds the digit,
+// so cells seeing both ends lose it. Chains are searched breadth first, so
+// the shortest chain from each start is reported.
+func xChain(g *domain.Grid) (domain.Hint, bool) {
+	for v := uint8(1); v <= 9; v++ {
+		adj := strongLinks(g, v)
+		for s := 0; s < 81; s++ {
+			if len(adj[s]) == 0 {
+				continue
+			}
+			// state cell*2+1 is reached by a strong link, cell*2 by a weak one
+			prev := make(map[int]int)
+			prev[s*2] = -1
+			queue := []int{s * 2}
+			for len(queue) > 0 {
+				st := queue[0]
+				queue = queue[1:]
+				cell, on := st/2, st%2 == 1
+				if on {
+					if path := chainPath(prev, st, 2); len(path) >= 4 && distinct(path) {
+						if elims := eliminations(g, v, s, cell); len(elims) > 0 {
+							return xChainHint(v, path, elims), true
+						}
+					}
+					for _, n := range domain.Peers[cell] {
+						if g.Cands[n].Has(v) {
+							if _, ok := prev[n*2]; !ok {
+								prev[n*2] = st
+								queue = append(queue, n*2)
+							}
+						}
+					}
+					continue
+				}
+				for _, n := range adj[cell] {
+					if _, ok := prev[n*2+1]; !ok {
+						prev[n*2+1] = st
+						queue = append(queue, n*2+1)
+					}
+				}
+			}
+		}
+	}
+	return domain.Hint{}, false
+}
+
+func xChainHint(v uint8, path []int, elims []domain.Candidate) domain.Hint {
+	links := make([]domain.Link, 0, len(path)-1)
+	for i := 0; i+1 < len(path); i++ {
+		if i%2 == 0 {
+			links = append(links, strong(path[i], v, path[i+1], v))
+		} else {
+			links = append(links, weak(path[i], v, path[i+1], v))
+		}
+	}
+	return domain.Hint{
+		Message: fmt.Sprintf("X-Chain on %d: %s; one end is %d, so remove %s",
+			v, chainText(path, "=", "-"), v, elimText(elims)),
+		Cells:        coords(path...),
+		Strategy:     domain.StrategyChains,
+		Technique:    "X-Chain",
+		Eliminations: elims,
+		Links:        links,
+	}
+}
+
+// xyChain finds a chain of bivalue cells, each seeing the next, where the
+// digit that is "on" in one cell is "off" in the next. If the first cell is
+// not z the last one is, so cells seeing both ends lose z.
+func xyChain(g *domain.Grid) (domain.Hint, bool) {
+	for s := 0; s < 81; s++ {
+		if g.Cands[s].Count() != 2 {
+			continue
+		}
+		for _, z := range g.Cands[s].List() {
+			// state cell*10+d: d is off in cell, its other candidate on
+			prev := map[int]int{s*10 + int(z): -1}
+			queue := []int{s*10 + int(z)}
+			for len(queue) > 0 {
+				st := queue[0]
+				queue = queue[1:]
+				cell, off := st/10, uint8(st%10)
+				on := g.Cands[cell].Without(off).First()
+				if on == z && cell != s {
+					if path := chainPath(prev, st, 10); len(path) >= 3 && distinct(path) {
+						if elims := eliminations(g, z, s, cell); len(elims) > 0 {
+							return xyChainHint(g, z, path, elims), true
+						}
+					}
+				}
+				for _, n := range domain.Peers[cell] {
+					if g.Cands[n].Count() != 2 || !g.Cands[n].Has(on) {
+						continue
+					}
+					ns := n*10 + int(on)
+					if _, ok := prev[ns]; !ok {
+						prev[ns] = st
+						queue = append(queue, ns)
+					}
+				}
+			}
+		}
+	}
+	return domain.Hint{}, false
+}
+
+func xyChainHint(g *domain.Grid, z uint8, path []int, elims []domain.Candidate) domain.Hint {
+	var links []domain.Link
+	off := z
+	for i, cell := range path {
+		on := g.Cands[cell].Without(off).First()
+		links = append(links, strong(cell, off, cell, on))
+		if i+1 < len(path) {
+			links = append(links, weak(cell, on, path[i+1], on))
+		}
+		off = on
+	}
+	return domain.Hint{
+		Message: fmt.Sprintf("XY-Chain: %s; one end is %d, so remove %s",
+			chainText(path, "-", "-"), z, elimText(elims)),
+		Cells:        coords(path...),
+		Strategy:     domain.StrategyChains,
+		Technique:    "XY-Chain",
+		Eliminations: elims,
+		Links:        links,
+	}
+}
+
+// chainPath follows prev back from state st and returns the cells from the
+// start; a state encodes its cell as st/per.
+func chainPath(prev map[int]int, st, per int) []int {
+	var rev []int
+	for ; st >= 0; st = prev[st] {
+		rev = append(rev, st/per)
+	}
+	path := make([]int, len(rev))
+	for i, s := range rev {
+		path[len(rev)-1-i] = s
+	}
+	return path
+}
+
+func distinct(path []int) bool {
+	seen := map[int]bool{}
+	for _, c := range path {
+		if seen[c] {
+			return false
+		}
+		seen[c] = true
+	}
+	return true
+}
+
+// chainText renders cells joined by alternating separators.
+func chainText(path []int, first, second string) string {
+	var sb strings.Builder
+	for i, cell := range path {
+		if i > 0 {
+			if i%2 == 1 {
+				sb.WriteString(first)
+			} else {
+				sb.WriteString(second)
+			}
+		}
+		sb.WriteString(cellName(cell))
+	}
+	return sb.String()
+}
@@ -0,0 +1,85 @@
+package hint
+
+import (
+	"context"
+	"testing"
+
+	"svw.info/sudoku/internal/domain"
+)
+
+// openGrid returns an empty grid where every cell allows every digit.
+func openGrid() domain.Grid {
+	var g domain.Grid
+	for i := range g.Cands {
+		g.Cands[i] = domain.AllDigits
+	}
+	return g
+}
+
+func run(t *testing.T, g *domain.Grid) domain.Hint {
+	t.Helper()
+	hh, ok, err := NewPipeline().run(context.Background(), g, domain.StrategyChains)
+	if err != nil || !ok {
+		t.Fatalf("no hint: ok=%v err=%v", ok, err)
+	}
+	return hh
+}
+
+func TestXYWing(t *testing.T) {
+	g := openGrid()
+	g.Cands[domain.CellIndex(0, 0)] = domain.DigitsOf(1, 2) // pivot
+	g.Cands[domain.CellIndex(0, 4)] = domain.DigitsOf(1, 3)
+	g.Cands[domain.CellIndex(4, 0)] = domain.DigitsOf(2, 3)
+
+	hh := run(t, &g)
+	if hh.Technique != "XY-Wing" || len(hh.Links) != 5 {
+		t.Fatalf("hint = %+v", hh)
+	}
+	want := domain.Candidate{Row: 4, Col: 4, Digit: 3}
+	if len(hh.Eliminations) != 1 || hh.Eliminations[0] != want {
+		t.Fatalf("eliminations = %v, want %v", hh.Eliminations, want)
+	}
+}
+
+func TestXChain(t *testing.T) {
+	g := openGrid()
+	// 1 is locked to r1/r6 in column 1 and r2/r6 in column 5
+	for r := 0; r < 9; r++ {
+		if r != 0 && r != 5 {
+			g.Eliminate(domain.CellIndex(r, 0), 1)
+		}
+		if r != 1 && r != 5 {
+			g.Eliminate(domain.CellIndex(r, 4), 1)
+		}
+	}
+
+	hh := run(t, &g)
+	if hh.Technique != "X-Chain" || len(hh.Links) != 3 || hh.Links[1].Kind != domain.LinkWeak {
+		t.Fatalf("hint = %+v", hh)
+	}
+	want := []domain.Candidate{{Row: 0, Col: 3, Digit: 1}, {Row: 0, Col: 5, Digit: 1}, {Row: 1, Col: 1, Digit: 1}, {Row: 1, Col: 2, Digit: 1}}
+	if len(hh.Eliminations) != len(want) {
+		t.Fatalf("eliminations = %v, want %v", hh.Eliminations, want)
+	}
+	for i := range want {
+		if hh.Eliminations[i] != want[i] {
+			t.Fatalf("eliminations = %v, want %v", hh.Eliminations, want)
+		}
+	}
+}
+
+func TestXYChain(t *testing.T) {
+	g := openGrid()
+	g.Cands[domain.CellIndex(0, 0)] = domain.DigitsOf(1, 2)
+	g.Cands[domain.CellIndex(0, 4)] = domain.DigitsOf(2, 3)
+	g.Cands[domain.CellIndex(4, 4)] = domain.DigitsOf(3, 4)
+	g.Cands[domain.CellIndex(4, 8)] = domain.DigitsOf(4, 1)
+
+	hh := run(t, &g)
+	if hh.Technique != "XY-Chain" || len(hh.Cells) != 4 || len(hh.Links) != 7 {
+		t.Fatalf("hint = %+v", hh)
+	}
+	if len(hh.Eliminations) != 2 || hh.Eliminations[0] != (domain.Candidate{Row: 0, Col: 8, Digit: 1}) {
+		t.Fatalf("eliminations = %v", hh.Eliminations)
+	}
+}
@@ -0,0 +1,208 @@
+package hint
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+
+	"svw.info/sudoku/internal/domain"
+)
+
+var fishNames = [5]string{2: "X-Wing", 3: "Swordfish", 4: "Jellyfish"}
+
+// basicFish finds n rows (or columns) in which a digit only fits in n
+// columns (rows): the digit is then locked to the crossings, and the rest
+// of the cover lines lose it.
+func basicFish(g *domain.Grid) (domain.Hint, bool) { return fish(g, false) }
+
+// finnedFish finds fish whose base lines have extra candidates, the fins,
+// all in one box. Either a fin is true or the fish holds, so cover-line
+// cells in the fin box lose the digit. Sashimi fish have a base line with
+// at most one candidate left in the cover lines.
+func finnedFish(g *domain.Grid) (domain.Hint, bool) { return fish(g, true) }
+
+func fish(g *domain.Grid, finned bool) (domain.Hint, bool) {
+	for n := 2; n <= 4; n++ {
+		for v := uint8(1); v <= 9; v++ {
+			for _, rows := range []bool{true, false} {
+				if hh, ok := fishOf(g, n, v, rows, finned); ok {
+					return hh, true
+				}
+			}
+		}
+	}
+	return domain.Hint{}, false
+}
+
+// lineCell returns cell j of row i, or of column i when rows is false.
+func lineCell(rows bool, i, j int) int {
+	if rows {
+		return i*9 + j
+	}
+	return j*9 + i
+}
+
+func fishOf(g *domain.Grid, n int, v uint8, rows, finned bool) (domain.Hint, bool) {
+	var pos [9]uint16 // positions of v in each line
+	for i := 0; i < 9; i++ {
+		for j := 0; j < 9; j++ {
+			if g.Cands[lineCell(rows, i, j)].Has(v) {
+				pos[i] |= 1 << j
+			}
+		}
+	}
+	for base := uint16(1); base < 1<<9; base++ {
+		if bits.OnesCount16(base) != n {
+			continue
+		}
+		var union uint16
+		empty := false
+		for i := 0; i < 9; i++ {
+			if base&(1<<i) != 0 {
+				empty = empty || pos[i] == 0
+				union |= pos[i]
+			}
+		}
+		if empty {
+			continue
+		}
+		size := bits.OnesCount16(union)
+		if !finned {
+			if size != n {
+				continue
+			}
+			if elims := fishElims(g, v, rows, base, union, -1); len(elims) > 0 {
+				return fishHint(v, rows, base, union, nil, false, elims, pos), true
+			}
+			continue
+		}
+		if size <= n {
+			continue
+		}
+		for cover := union; cover > 0; cover = (cover - 1) & union {
+			if bits.OnesCount16(cover) != n {
+				continue
+			}
+			var fins []int
+			box := -1
+			for i := 0; i < 9 && box != -2; i++ {
+				if base&(1<<i) == 0 {
+					continue
+				}
+				for j := 0; j < 9; j++ {
+					if pos[i]&^cover&(1<<j) == 0 {
+						continue
+					}
+					cell := lineCell(rows, i, j)
+					fb := domain.CellHouses[cell][2]
+					if box >= 0 && fb != box {
+						box = -2
+						break
+					}
+					box = fb
+					fins = append(fins, cell)
+				}
+			}
+			if box < 0 {
+				continue
+			}
+			sashimi := false
+			for i := 0; i < 9; i++ {
+				if base&(1<<i) != 0 && bits.OnesCount16(pos[i]&cover) <= 1 {
+					sashimi = true
+				}
+			}
+			if elims := fishElims(g, v, rows, base, cover, box); len(elims) > 0 {
+				return fishHint(v, rows, base, cover, fins, sashimi, elims, pos), true
+			}
+		}
+	}
+	return domain.Hint{}, false
+}
+
+// fishElims lists v in the cover lines outside the base lines, limited to
+// box when it is not -1.
+func fishElims(g *domain.Grid, v uint8, rows bool, base, cover uint16, box int) []domain.Candidate {
+	var out []domain.Candidate
+	for cell := 0; cell < 81; cell++ {
+		i, j := cell/9, cell%9
+		if !rows {
+			i, j = j, i
+		}
+		if base&(1<<i) != 0 || cover&(1<<j) == 0 || !g.Cands[cell].Has(v) {
+			continue
+		}
+		if box >= 0 && domain.CellHouses[cell][2] != box {
+			continue
+		}
+		out = append(out, cand(cell, v))
+	}
+	return out
+}
+
+func fishHint(v uint8, rows bool, base, cover uint16, fins []int, sashimi bool, elims []domain.Candidate, pos [9]uint16) domain.Hint {
+	baseKind, coverKind := domain.HouseRow, domain.HouseCol
+	if !rows {
+		baseKind, coverKind = coverKind, baseKind
+	}
+	n := bits.OnesCount16(base)
+	f := &domain.Fish{Base: lines(baseKind, base), Cover: lines(coverKind, cover), Fins: coords(fins...)}
+	var cells []int
+	for i := 0; i < 9; i++ {
+		for j := 0; j < 9; j++ {
+			if base&(1<<i) != 0 && pos[i]&(1<<j) != 0 {
+				cells = append(cells, lineCell(rows, i, j))
+			}
+		}
+	}
+	name, tier := fishNames[n], domain.StrategyXWing
+	msg := fmt.Sprintf("%s on %d: in %s, %d only fits in %s; remove %s",
+		name, v, lineText(baseKind, base), v, lineText(coverKind, cover), elimText(elims))
+	if len(fins) > 0 {
+		kind := "finned"
+		if sashimi {
+			kind = "sashimi"
+		}
+		name, tier = kind+" "+name, domain.StrategyFinnedFish
+		fs := make([]string, len(fins))
+		for i, c := range fins {
+			fs[i] = cellName(c)
+		}
+		msg = fmt.Sprintf("%s on %d: in %s, %d only fits in %s or the fins %s; remove %s",
+			name, v, lineText(baseKind, base), v, lineText(coverKind, cover), strings.Join(fs, ", "), elimText(elims))
+	}
+	return domain.Hint{
+		Message:      msg,
+		Cells:        coords(cells...),
+		Strategy:     tier,
+		Technique:    name,
+		Eliminations: elims,
+		Fish:         f,
+	}
+}
+
+func lines(kind domain.HouseKind, set uint16) []domain.House {
+	var out []domain.House
+	for i := 0; i < 9; i++ {
+		if set&(1<<i) != 0 {
+			out = append(out, domain.House{Kind: kind, Index: i})
+		}
+	}
+	return out
+}
+
+// lineText renders a set of lines as "rows 1, 5 and 8".
+func lineText(kind domain.HouseKind, set uint16) string {
+	var nums []string
+	for i := 0; i < 9; i++ {
+		if set&(1<<i) != 0 {
+			nums = append(nums, fmt.Sprint(i+1))
+		}
+	}
+	word := "rows"
+	if kind == domain.HouseCol {
+		word = "columns"
+	}
+	last := len(nums) - 1
+	return fmt.Sprintf("%s %s and %s", word, strings.Join(nums[:last], ", "), nums[last])
+}
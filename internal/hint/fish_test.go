@@ -0,0 +1,54 @@
+package hint
+
+import (
+	"testing"
+
+	"svw.info/sudoku/internal/domain"
+)
+
+// lockRow leaves v in row r only at the given columns.
+func lockRow(g *domain.Grid, v uint8, r int, cols ...int) {
+	for c := 0; c < 9; c++ {
+		if !contains(cols, c) {
+			g.Eliminate(domain.CellIndex(r, c), v)
+		}
+	}
+}
+
+func TestXWing(t *testing.T) {
+	g := openGrid()
+	lockRow(&g, 1, 0, 1, 6)
+	lockRow(&g, 1, 4, 1, 6)
+
+	hh, ok := basicFish(&g)
+	if !ok || hh.Technique != "X-Wing" || hh.Strategy != domain.StrategyXWing {
+		t.Fatalf("hint = %+v", hh)
+	}
+	if len(hh.Fish.Base) != 2 || hh.Fish.Base[1] != (domain.House{Kind: domain.HouseRow, Index: 4}) || len(hh.Fish.Fins) != 0 {
+		t.Fatalf("fish = %+v", hh.Fish)
+	}
+	if len(hh.Eliminations) != 14 {
+		t.Fatalf("eliminations = %v", hh.Eliminations)
+	}
+}
+
+func TestFinnedXWing(t *testing.T) {
+	g := openGrid()
+	lockRow(&g, 1, 0, 1, 6)
+	lockRow(&g, 1, 4, 1, 6, 7) // fin at r5c8 in box 6
+
+	if hh, ok := basicFish(&g); ok {
+		t.Fatalf("unexpected basic fish %+v", hh)
+	}
+	hh, ok := finnedFish(&g)
+	if !ok || hh.Technique != "finned X-Wing" || hh.Strategy != domain.StrategyFinnedFish {
+		t.Fatalf("hint = %+v", hh)
+	}
+	if len(hh.Fish.Fins) != 1 || hh.Fish.Fins[0] != (domain.CellCoord{Row: 4, Col: 7}) {
+		t.Fatalf("fins = %v", hh.Fish.Fins)
+	}
+	want := []domain.Candidate{{Row: 3, Col: 6, Digit: 1}, {Row: 5, Col: 6, Digit: 1}}
+	if len(hh.Eliminations) != 2 || hh.Eliminations[0] != want[0] || hh.Eliminations[1] != want[1] {
+		t.Fatalf("eliminations = %v, want %v", hh.Eliminations, want)
+	}
+}
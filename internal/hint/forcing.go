@@ -0,0 +1,124 @@
+package hint
+
+import (
+	"fmt"
+	"strings"
+
+	"svw.info/sudoku/internal/domain"
+)
+
+// ForcingTechnique names hints found by Forced.
+const ForcingTechnique = "forcing chain"
+
+// Forced is a last resort for when no strategy applies. It looks for a cell
+// whose value is forced because every other candidate leads to a
+// contradiction within depth placements of singles, and explains each of
+// those chains. Shorter chains and cells with fewer candidates are tried
+// first. With cands set the search starts from the player's pencil marks.
+func Forced(b *domain.Board, cands *domain.Marks, depth int) (domain.Hint, bool) {
+	var g domain.Grid
+	if cands != nil {
+		g, _ = domain.GridFromMarks(b, cands)
+	} else {
+		g, _ = domain.NewGrid(b)
+	}
+	for d := 0; d <= depth; d++ {
+		for n := 2; n <= 9; n++ {
+			for cell := 0; cell < 81; cell++ {
+				if g.Values[cell] != 0 || g.Cands[cell].Count() != n {
+					continue
+				}
+				if hh, ok := forcedCell(&g, cell, d); ok {
+					return hh, true
+				}
+			}
+		}
+	}
+	return domain.Hint{}, false
+}
+
+func forcedCell(g *domain.Grid, cell, depth int) (domain.Hint, bool) {
+	var (
+		survivor uint8
+		alive    int
+		why      []string
+	)
+	for _, v := range g.Cands[cell].List() {
+		trial := *g
+		steps, bad := propagate(&trial, cell, v, depth)
+		if bad == "" {
+			survivor = v
+			if alive++; alive > 1 {
+				return domain.Hint{}, false
+			}
+			continue
+		}
+		msg := fmt.Sprintf("if %s were %d", cellName(cell), v)
+		if len(steps) > 0 {
+			msg += ", then " + strings.Join(steps, ", ")
+		}
+		why = append(why, msg+", and "+bad+": contradiction")
+	}
+	if alive != 1 {
+		return domain.Hint{}, false
+	}
+	r, c := cell/9, cell%9
+	return domain.Hint{
+		Message: fmt.Sprintf("No logical step applies, so try each candidate: %s. So %s is %d",
+			strings.Join(why, "; "), cellName(cell), survivor),
+		Cells:     coords(cell),
+		Strategy:  domain.StrategyLastResort,
+		Technique: ForcingTechnique,
+		House:     &domain.House{Kind: domain.HouseBox, Index: (r/3)*3 + c/3},
+		Digit:     survivor,
+	}, true
+}
+
+// propagate places v in cell and then singles, at most depth of them. It
+// returns the placements made and a description of the contradiction it ran
+// into, or "" when none showed up.
+func propagate(g *domain.Grid, cell int, v uint8, depth int) ([]string, string) {
+	g.Place(cell, v)
+	var steps []string
+	for {
+		if bad := contradiction(g); bad != "" {
+			return steps, bad
+		}
+		if len(steps) == depth {
+			return steps, ""
+		}
+		hh, ok := nakedSingle(g)
+		if !ok {
+			hh, ok = hiddenSingle(g)
+		}
+		if !ok {
+			return steps, ""
+		}
+		next := domain.CellIndex(hh.Cells[0].Row, hh.Cells[0].Col)
+		g.Place(next, hh.Digit)
+		steps = append(steps, fmt.Sprintf("%s=%d", cellName(next), hh.Digit))
+	}
+}
+
+// contradiction describes the first empty cell without candidates or digit
+// without a place in some house, or returns "".
+func contradiction(g *domain.Grid) string {
+	for cell := 0; cell < 81; cell++ {
+		if g.Values[cell] == 0 && g.Cands[cell] == 0 {
+			return cellName(cell) + " has no candidates left"
+		}
+	}
+	for h := range domain.Houses {
+		var seen domain.Digits
+		for _, cell := range domain.Houses[h] {
+			seen |= g.Cands[cell]
+			if v := g.Values[cell]; v != 0 {
+				seen = seen.With(v)
+			}
+		}
+		if missing := domain.AllDigits &^ seen; missing != 0 {
+			return fmt.Sprintf("%d has no place left in %s", missing.First(), domain.HouseAt(h))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,34 @@
+package hint
+
+import (
+	"strings"
+	"testing"
+
+	"svw.info/sudoku/internal/domain"
+)
+
+func TestForcedExplainsContradiction(t *testing.T) {
+	// r1c1 is 1 or 2; 2 would leave r1c2 (only 2 left) without candidates.
+	b := &domain.Board{}
+	var marks domain.Marks
+	for r := 0; r < 9; r++ {
+		for c := 0; c < 9; c++ {
+			marks[r][c] = domain.AllDigits
+		}
+	}
+	marks[0][0] = domain.DigitsOf(1, 2)
+	marks[0][1] = domain.DigitsOf(2, 3)
+	marks[0][2] = domain.DigitsOf(3, 2)
+
+	hh, ok := Forced(b, &marks, 4)
+	if !ok || hh.Technique != ForcingTechnique || hh.Strategy != domain.StrategyLastResort {
+		t.Fatalf("hint = %+v", hh)
+	}
+	if hh.Cells[0] != (domain.CellCoord{Row: 0, Col: 0}) || hh.Digit != 1 {
+		t.Fatalf("forced r%dc%d=%d, want r1c1=1", hh.Cells[0].Row+1, hh.Cells[0].Col+1, hh.Digit)
+	}
+	want := "if r1c1 were 2, then r1c2=3, and r1c3 has no candidates left: contradiction"
+	if !strings.Contains(hh.Message, want) {
+		t.Fatalf("message %q lacks %q", hh.Message, want)
+	}
+}
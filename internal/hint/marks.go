@@ -0,0 +1,31 @@
+package hint
+
+import "svw.info/sudoku/internal/domain"
+
+// CheckMarks compares the player's pencil marks with the true candidates.
+// Only empty cells that carry at least one mark are checked: a cell without
+// notes has not been annotated yet. A mark is impossible when a placed peer
+// already holds the digit; the solution digit is missing when it isn't marked.
+// A nil solution only checks for impossible marks.
+func CheckMarks(b *domain.Board, marks *domain.Marks, solution *domain.Board) domain.MarksReport {
+	var rep domain.MarksReport
+	g, _ := domain.NewGrid(b)
+	for cell := 0; cell < 81; cell++ {
+		r, c := cell/9, cell%9
+		if g.Values[cell] != 0 || marks[r][c] == 0 {
+			continue
+		}
+		for _, v := range (marks[r][c] & domain.AllDigits).List() {
+			if !g.Allowed(cell, v) {
+				rep.Impossible = append(rep.Impossible, domain.Candidate{Row: r, Col: c, Digit: v})
+			}
+		}
+		if solution == nil {
+			continue
+		}
+		if want := solution.Values[r][c]; want != 0 && !marks.Has(r, c, want) {
+			rep.Missing = append(rep.Missing, domain.Candidate{Row: r, Col: c, Digit: want})
+		}
+	}
+	return rep
+}
@@ -0,0 +1,159 @@
+package hint
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/ports"
+)
+
+// Pipeline is a Hinter that tries its strategies from the simplest tier up
+// and returns the first step found within the requested tier.
+type Pipeline struct {
+	steps []step
+	// Solver enables the uniqueness strategies when set: they only run on
+	// boards that Solver.Unique confirms have a single solution.
+	Solver ports.Solver
+}
+
+type step struct {
+	tier   domain.StrategyTier
+	find   func(g *domain.Grid) (domain.Hint, bool)
+	unique bool // valid only for puzzles with one solution
+}
+
+// NewPipeline returns a hinter with every strategy of this package.
+func NewPipeline() *Pipeline {
+	return &Pipeline{steps: []step{
+		{domain.StrategySingles, nakedSingle, false},
+		{domain.StrategySingles, hiddenSingle, false},
+		{domain.StrategyXWing, basicFish, false},
+		{domain.StrategyChains, xyWing, false},
+		{domain.StrategyChains, xyzWing, false},
+		{domain.StrategyChains, wWing, false},
+		{domain.StrategyChains, simpleColoring, false},
+		{domain.StrategyChains, xChain, false},
+		{domain.StrategyChains, xyChain, false},
+		{domain.StrategyFinnedFish, finnedFish, false},
+		{domain.StrategyUniqueness, uniqueRectangle, true},
+		{domain.StrategyUniqueness, bugPlusOne, true},
+	}}
+}
+
+func (p *Pipeline) Hint(ctx context.Context, b *domain.Board, max domain.StrategyTier) (domain.Hint, bool, error) {
+	g, _ := domain.NewGrid(b)
+	return p.run(ctx, &g, max)
+}
+
+// HintFrom runs the strategies against the player's own candidates.
+func (p *Pipeline) HintFrom(ctx context.Context, b *domain.Board, cands *domain.Marks, max domain.StrategyTier) (domain.Hint, bool, error) {
+	g, _ := domain.GridFromMarks(b, cands)
+	return p.run(ctx, &g, max)
+}
+
+func (p *Pipeline) run(ctx context.Context, g *domain.Grid, max domain.StrategyTier) (domain.Hint, bool, error) {
+	checked, unique := false, false
+	for _, s := range p.steps {
+		if s.tier > max {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return domain.Hint{}, false, err
+		}
+		if s.unique {
+			if p.Solver == nil {
+				continue
+			}
+			if !checked {
+				b := g.Board()
+				ok, _, err := p.Solver.Unique(ctx, &b)
+				if err != nil {
+					return domain.Hint{}, false, err
+				}
+				checked, unique = true, ok
+			}
+			if !unique {
+				continue
+			}
+		}
+		if hh, ok := s.find(g); ok {
+			return hh, true, nil
+		}
+	}
+	return domain.Hint{}, false, nil
+}
+
+// sees reports whether two distinct cells share a house.
+func sees(a, b int) bool {
+	if a == b {
+		return false
+	}
+	ha, hb := domain.CellHouses[a], domain.CellHouses[b]
+	return ha[0] == hb[0] || ha[1] == hb[1] || ha[2] == hb[2]
+}
+
+// eliminations lists the candidates v of cells that see every cell of from.
+func eliminations(g *domain.Grid, v uint8, from ...int) []domain.Candidate {
+	var out []domain.Candidate
+	for cell := 0; cell < 81; cell++ {
+		if !g.Cands[cell].Has(v) {
+			continue
+		}
+		all := true
+		for _, f := range from {
+			if !sees(cell, f) {
+				all = false
+				break
+			}
+		}
+		if all {
+			out = append(out, cand(cell, v))
+		}
+	}
+	return out
+}
+
+func cand(cell int, v uint8) domain.Candidate {
+	return domain.Candidate{Row: cell / 9, Col: cell % 9, Digit: v}
+}
+
+func coords(cells ...int) []domain.CellCoord {
+	out := make([]domain.CellCoord, len(cells))
+	for i, cell := range cells {
+		out[i] = domain.Coord(cell)
+	}
+	return out
+}
+
+func cellName(cell int) string { return fmt.Sprintf("r%dc%d", cell/9+1, cell%9+1) }
+
+// elimText describes eliminations as "7 from r2c1, r3c1", or as
+// "r2c1(3), r2c5(7)" when they remove different digits.
+func elimText(elims []domain.Candidate) string {
+	if len(elims) == 0 {
+		return ""
+	}
+	names := make([]string, len(elims))
+	mixed := false
+	for i, e := range elims {
+		names[i] = cellName(domain.CellIndex(e.Row, e.Col))
+		mixed = mixed || e.Digit != elims[0].Digit
+	}
+	if mixed {
+		for i, e := range elims {
+			names[i] += fmt.Sprintf("(%d)", e.Digit)
+		}
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%d from %s", elims[0].Digit, strings.Join(names, ", "))
+}
+
+func strong(a int, va uint8, b int, vb uint8) domain.Link {
+	return domain.Link{From: cand(a, va), To: cand(b, vb), Kind: domain.LinkStrong}
+}
+
+func weak(a int, va uint8, b int, vb uint8) domain.Link {
+	return domain.Link{From: cand(a, va), To: cand(b, vb), Kind: domain.LinkWeak}
+}
@@ -7,65 +7,100 @@ import (
 	"svw.info/sudoku/internal/domain"
 )
 
-// Singles implements a minimal Hinter that suggests naked singles.
+// Singles implements a minimal Hinter that suggests naked and hidden singles.
 type Singles struct{}
 
 func NewSingles() *Singles { return &Singles{} }
 
-// Hint returns the first found naked single if max tier allows it.
+// Hint returns the first naked single, else the first hidden single, if max tier allows it.
 func (h *Singles) Hint(ctx context.Context, b *domain.Board, max domain.StrategyTier) (domain.Hint, bool, error) {
+	g, _ := domain.NewGrid(b)
+	return h.run(&g, max)
+}
+
+// HintFrom runs the same strategies against the player's own candidates
+// instead of recomputing them from the placed values.
+func (h *Singles) HintFrom(ctx context.Context, b *domain.Board, cands *domain.Marks, max domain.StrategyTier) (domain.Hint, bool, error) {
+	g, _ := domain.GridFromMarks(b, cands)
+	return h.run(&g, max)
+}
+
+func (h *Singles) run(g *domain.Grid, max domain.StrategyTier) (domain.Hint, bool, error) {
 	if max < domain.StrategySingles {
 		return domain.Hint{}, false, nil
 	}
-	for r := 0; r < 9; r++ {
-		for c := 0; c < 9; c++ {
-			if b.Values[r][c] != 0 {
-				continue
-			}
-			v, ok := soleCandidate(b, r, c)
-			if ok {
-				msg := fmt.Sprintf("Single: only %d fits here", v)
-				return domain.Hint{
-					Message:  msg,
-					Cells:    []domain.CellCoord{{Row: r, Col: c}},
-					Strategy: domain.StrategySingles,
-				}, true, nil
-			}
-		}
+	if hh, ok := nakedSingle(g); ok {
+		return hh, true, nil
+	}
+	if hh, ok := hiddenSingle(g); ok {
+		return hh, true, nil
 	}
 	return domain.Hint{}, false, nil
 }
 
-func soleCandidate(b *domain.Board, r, c int) (uint8, bool) {
-	var last uint8
-	count := 0
-	for v := uint8(1); v <= 9; v++ {
-		if allowed(b, r, c, v) {
-			count++
-			last = v
-			if count > 1 {
-				return 0, false
-			}
+// nakedSingle finds an empty cell with exactly one candidate.
+func nakedSingle(g *domain.Grid) (domain.Hint, bool) {
+	for cell := 0; cell < 81; cell++ {
+		if g.Values[cell] != 0 {
+			continue
 		}
+		v, ok := g.Cands[cell].Single()
+		if !ok {
+			continue
+		}
+		r, c := cell/9, cell%9
+		return domain.Hint{
+			Message:   fmt.Sprintf("Single: only %d fits here", v),
+			Cells:     []domain.CellCoord{{Row: r, Col: c}},
+			Strategy:  domain.StrategySingles,
+			Technique: "naked single",
+			House:     &domain.House{Kind: domain.HouseBox, Index: (r/3)*3 + c/3},
+			Digit:     v,
+		}, true
 	}
-	return last, count == 1
+	return domain.Hint{}, false
 }
 
-func allowed(b *domain.Board, r, c int, v uint8) bool {
-	// row & col
-	for i := 0; i < 9; i++ {
-		if b.Values[r][i] == v || b.Values[i][c] == v {
-			return false
-		}
-	}
-	// box
-	br, bc := (r/3)*3, (c/3)*3
-	for dr := 0; dr < 3; dr++ {
-		for dc := 0; dc < 3; dc++ {
-			if b.Values[br+dr][bc+dc] == v {
-				return false
+// hiddenSingle finds a digit that fits in only one cell of a row, column or box.
+func hiddenSingle(g *domain.Grid) (domain.Hint, bool) {
+	// boxes first: they are the easiest for players to scan
+	for _, h := range houseOrder {
+		house := domain.HouseAt(h)
+		for v := uint8(1); v <= 9; v++ {
+			spot, n := -1, 0
+			for _, cell := range domain.Houses[h] {
+				if g.Values[cell] == v {
+					n = -1
+					break
+				}
+				if g.Cands[cell].Has(v) {
+					spot = cell
+					n++
+				}
+			}
+			if n == 1 {
+				return domain.Hint{
+					Message:   fmt.Sprintf("Hidden single: %d fits only here in %s", v, house),
+					Cells:     []domain.CellCoord{domain.Coord(spot)},
+					Strategy:  domain.StrategySingles,
+					Technique: "hidden single",
+					House:     &house,
+					Digit:     v,
+				}, true
 			}
 		}
 	}
-	return true
-}
\ No newline at end of file
+	return domain.Hint{}, false
+}
+
+// houseOrder scans boxes, then rows, then columns.
+var houseOrder = func() []int {
+	out := make([]int, 0, 27)
+	for h := 18; h < 27; h++ {
+		out = append(out, h)
+	}
+	for h := 0; h < 18; h++ {
+		out = append(out, h)
+	}
+	return out
+}()
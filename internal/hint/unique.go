@@ -0,0 +1,255 @@
+package hint
+
+import (
+	"fmt"
+
+	"svw.info/sudoku/internal/domain"
+)
+
+// The strategies in this file rely on the puzzle having a single solution:
+// they avoid "deadly patterns" that would allow two. Pipeline only runs them
+// once its Solver has confirmed uniqueness.
+
+// rectangles calls fn for each set of four empty cells on two rows, two
+// columns and exactly two boxes that share two candidates a and b. corners
+// are ordered r1c1, r1c2, r2c1, r2c2.
+func rectangles(g *domain.Grid, fn func(corners [4]int, a, b uint8) (domain.Hint, bool)) (domain.Hint, bool) {
+	for r1 := 0; r1 < 9; r1++ {
+		for r2 := r1 + 1; r2 < 9; r2++ {
+			for c1 := 0; c1 < 9; c1++ {
+				for c2 := c1 + 1; c2 < 9; c2++ {
+					if (r1/3 == r2/3) == (c1/3 == c2/3) {
+						continue // one box or four boxes
+					}
+					corners := [4]int{r1*9 + c1, r1*9 + c2, r2*9 + c1, r2*9 + c2}
+					common := domain.AllDigits
+					for _, cell := range corners {
+						common &= g.Cands[cell]
+					}
+					ds := common.List()
+					for i, a := range ds {
+						for _, b := range ds[i+1:] {
+							if hh, ok := fn(corners, a, b); ok {
+								return hh, true
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return domain.Hint{}, false
+}
+
+func urHint(kind int, corners [4]int, a, b uint8, why string, elims []domain.Candidate) domain.Hint {
+	return domain.Hint{
+		Message: fmt.Sprintf("Unique rectangle type %d on %s, %s, %s, %s: if all four were %d/%d they could swap and the puzzle would have two solutions; %s, so remove %s",
+			kind, cellName(corners[0]), cellName(corners[1]), cellName(corners[2]), cellName(corners[3]), a, b, why, elimText(elims)),
+		Cells:        coords(corners[:]...),
+		Strategy:     domain.StrategyUniqueness,
+		Technique:    fmt.Sprintf("unique rectangle type %d", kind),
+		Eliminations: elims,
+	}
+}
+
+// uniqueRectangle finds unique rectangle types 1 to 4. Types 2 to 4 need a
+// floor of two {a,b} cells on one line; the other two corners form the roof.
+func uniqueRectangle(g *domain.Grid) (domain.Hint, bool) {
+	return rectangles(g, func(corners [4]int, a, b uint8) (domain.Hint, bool) {
+		ab := domain.DigitsOf(a, b)
+		var extra []int
+		for _, cell := range corners {
+			if g.Cands[cell] != ab {
+				extra = append(extra, cell)
+			}
+		}
+		switch len(extra) {
+		case 1:
+			// type 1: the odd corner must not be a or b
+			var elims []domain.Candidate
+			for _, v := range []uint8{a, b} {
+				elims = append(elims, cand(extra[0], v))
+			}
+			why := fmt.Sprintf("%s must hold one of its other candidates", cellName(extra[0]))
+			return urHint(1, corners, a, b, why, elims), true
+		case 2:
+			r1, r2 := extra[0], extra[1]
+			if !sees(r1, r2) {
+				return domain.Hint{}, false // diagonal roof
+			}
+			return urRoof(g, corners, a, b, r1, r2)
+		}
+		return domain.Hint{}, false
+	})
+}
+
+// urRoof handles types 2 to 4 for a roof r1, r2 sharing a house.
+func urRoof(g *domain.Grid, corners [4]int, a, b uint8, r1, r2 int) (domain.Hint, bool) {
+	ab := domain.DigitsOf(a, b)
+	x1, x2 := g.Cands[r1]&^ab, g.Cands[r2]&^ab
+	roof := fmt.Sprintf("%s or %s", cellName(r1), cellName(r2))
+
+	// type 2: both roof cells carry the same single extra x
+	if x1 == x2 {
+		if x, ok := x1.Single(); ok {
+			if elims := eliminations(g, x, r1, r2); len(elims) > 0 {
+				why := fmt.Sprintf("one of %s must be %d", roof, x)
+				return urHint(2, corners, a, b, why, elims), true
+			}
+		}
+	}
+
+	shared := sharedHouses(r1, r2)
+	// type 3: the roof's extras form a naked subset with other cells of a shared house
+	extras := x1 | x2
+	for _, h := range shared {
+		var others []int
+		for _, cell := range domain.Houses[h] {
+			if cell != r1 && cell != r2 && g.Values[cell] == 0 {
+				others = append(others, cell)
+			}
+		}
+		for k := 1; k <= 3 && k < len(others); k++ {
+			if hh, ok := urSubset(g, corners, a, b, r1, r2, h, extras, others, k); ok {
+				return hh, true
+			}
+		}
+	}
+
+	// type 4: a is locked to the roof in a shared house, so neither roof cell can be b
+	for _, h := range shared {
+		for _, pair := range [][2]uint8{{a, b}, {b, a}} {
+			locked, other := pair[0], pair[1]
+			c, d, ok := conjugate(g, h, locked)
+			if !ok || !(c == r1 && d == r2 || c == r2 && d == r1) {
+				continue
+			}
+			elims := []domain.Candidate{cand(r1, other), cand(r2, other)}
+			why := fmt.Sprintf("%d only fits in %s within %s", locked, roof, domain.HouseAt(h))
+			return urHint(4, corners, a, b, why, elims), true
+		}
+	}
+	return domain.Hint{}, false
+}
+
+// urSubset looks for k cells of house h that, together with the roof's
+// extra digits, hold exactly k+1 digits.
+func urSubset(g *domain.Grid, corners [4]int, a, b uint8, r1, r2, h int, extras domain.Digits, others []int, k int) (domain.Hint, bool) {
+	var pick func(start int, chosen []int, set domain.Digits) (domain.Hint, bool)
+	pick = func(start int, chosen []int, set domain.Digits) (domain.Hint, bool) {
+		if len(chosen) == k {
+			if set.Count() != k+1 {
+				return domain.Hint{}, false
+			}
+			var elims []domain.Candidate
+			for _, cell := range others {
+				if contains(chosen, cell) {
+					continue
+				}
+				for _, v := range (g.Cands[cell] & set).List() {
+					elims = append(elims, cand(cell, v))
+				}
+			}
+			if len(elims) == 0 {
+				return domain.Hint{}, false
+			}
+			names := make([]string, len(chosen))
+			for i, c := range chosen {
+				names[i] = cellName(c)
+			}
+			why := fmt.Sprintf("%s or %s holds an extra digit, forming a naked set %v with %v in %s",
+				cellName(r1), cellName(r2), set.List(), names, domain.HouseAt(h))
+			return urHint(3, corners, a, b, why, elims), true
+		}
+		for i := start; i < len(others); i++ {
+			next := set | g.Cands[others[i]]
+			if next.Count() > k+1 {
+				continue
+			}
+			if hh, ok := pick(i+1, append(chosen, others[i]), next); ok {
+				return hh, true
+			}
+		}
+		return domain.Hint{}, false
+	}
+	return pick(0, nil, extras)
+}
+
+// sharedHouses returns the houses containing both cells.
+func sharedHouses(a, b int) []int {
+	var out []int
+	for i := 0; i < 3; i++ {
+		if domain.CellHouses[a][i] == domain.CellHouses[b][i] {
+			out = append(out, domain.CellHouses[a][i])
+		}
+	}
+	return out
+}
+
+// bugPlusOne handles a bivalue universal grave plus one: every empty cell
+// has two candidates except one with three, and without one of those three
+// each digit would be a candidate in exactly two cells of every house it is
+// open in. That grid would be deadly, so the odd cell takes the digit.
+func bugPlusOne(g *domain.Grid) (domain.Hint, bool) {
+	odd := -1
+	for cell := 0; cell < 81; cell++ {
+		if g.Values[cell] != 0 {
+			continue
+		}
+		switch g.Cands[cell].Count() {
+		case 2:
+		case 3:
+			if odd >= 0 {
+				return domain.Hint{}, false
+			}
+			odd = cell
+		default:
+			return domain.Hint{}, false
+		}
+	}
+	if odd < 0 {
+		return domain.Hint{}, false
+	}
+	for _, v := range g.Cands[odd].List() {
+		if !graveWithout(g, odd, v) {
+			continue
+		}
+		house := domain.HouseAt(domain.CellHouses[odd][0])
+		return domain.Hint{
+			Message: fmt.Sprintf("BUG+1: every other empty cell has two candidates; if %s were not %d, each digit would appear exactly twice in every house and the puzzle would have two solutions, so it is %d",
+				cellName(odd), v, v),
+			Cells:     coords(odd),
+			Strategy:  domain.StrategyUniqueness,
+			Technique: "BUG+1",
+			House:     &house,
+			Digit:     v,
+		}, true
+	}
+	return domain.Hint{}, false
+}
+
+// graveWithout reports whether removing candidate v from cell odd leaves
+// every digit a candidate in exactly zero or two cells of each house.
+func graveWithout(g *domain.Grid, odd int, v uint8) bool {
+	for _, house := range domain.Houses {
+		var n [10]int
+		for _, cell := range house {
+			if g.Values[cell] != 0 {
+				continue
+			}
+			cands := g.Cands[cell]
+			if cell == odd {
+				cands = cands.Without(v)
+			}
+			for _, d := range cands.List() {
+				n[d]++
+			}
+		}
+		for _, c := range n {
+			if c != 0 && c != 2 {
+				return false
+			}
+		}
+	}
+	return true
+}
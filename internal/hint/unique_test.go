@@ -0,0 +1,141 @@
+package hint
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/ports"
+)
+
+type uniqueSolver bool
+
+func (s uniqueSolver) Solve(ctx context.Context, b *domain.Board) (*domain.Board, ports.Stats, error) {
+	return b, ports.Stats{}, nil
+}
+
+func (s uniqueSolver) Unique(ctx context.Context, b *domain.Board) (bool, ports.Stats, error) {
+	return bool(s), ports.Stats{}, nil
+}
+
+func TestUniqueRectangleType1(t *testing.T) {
+	g := openGrid()
+	g.Cands[domain.CellIndex(0, 0)] = domain.DigitsOf(1, 2)
+	g.Cands[domain.CellIndex(0, 1)] = domain.DigitsOf(1, 2)
+	g.Cands[domain.CellIndex(3, 0)] = domain.DigitsOf(1, 2)
+	g.Cands[domain.CellIndex(3, 1)] = domain.DigitsOf(1, 2, 5)
+
+	ctx := context.Background()
+	p := NewPipeline()
+	if hh, ok, _ := p.run(ctx, &g, domain.StrategyUniqueness); ok && hh.Strategy == domain.StrategyUniqueness {
+		t.Fatalf("uniqueness hint without a solver: %+v", hh)
+	}
+	p.Solver = uniqueSolver(false)
+	if hh, ok, _ := p.run(ctx, &g, domain.StrategyUniqueness); ok && hh.Strategy == domain.StrategyUniqueness {
+		t.Fatalf("uniqueness hint on a non-unique board: %+v", hh)
+	}
+
+	p.Solver = uniqueSolver(true)
+	hh, ok, err := p.run(ctx, &g, domain.StrategyUniqueness)
+	if err != nil || !ok || hh.Technique != "unique rectangle type 1" {
+		t.Fatalf("hint = %+v, ok=%v, err=%v", hh, ok, err)
+	}
+	want := []domain.Candidate{{Row: 3, Col: 1, Digit: 1}, {Row: 3, Col: 1, Digit: 2}}
+	if len(hh.Eliminations) != 2 || hh.Eliminations[0] != want[0] || hh.Eliminations[1] != want[1] {
+		t.Fatalf("eliminations = %v, want %v", hh.Eliminations, want)
+	}
+}
+
+func TestUniqueRectangleType2(t *testing.T) {
+	g := openGrid()
+	g.Cands[domain.CellIndex(0, 0)] = domain.DigitsOf(1, 2)
+	g.Cands[domain.CellIndex(0, 1)] = domain.DigitsOf(1, 2)
+	g.Cands[domain.CellIndex(3, 0)] = domain.DigitsOf(1, 2, 5)
+	g.Cands[domain.CellIndex(3, 1)] = domain.DigitsOf(1, 2, 5)
+
+	hh, ok := uniqueRectangle(&g)
+	if !ok || hh.Technique != "unique rectangle type 2" {
+		t.Fatalf("hint = %+v", hh)
+	}
+	// 5 goes from every cell seeing both roof cells: the rest of row 4 and box 4
+	for _, c := range []domain.Candidate{{Row: 3, Col: 2, Digit: 5}, {Row: 3, Col: 8, Digit: 5}, {Row: 5, Col: 1, Digit: 5}} {
+		if !slices.Contains(hh.Eliminations, c) {
+			t.Errorf("eliminations %v miss %v", hh.Eliminations, c)
+		}
+	}
+	for _, c := range hh.Eliminations {
+		if c.Digit != 5 || c.Row == 3 && c.Col < 2 || c.Row != 3 && (c.Row > 5 || c.Col > 2) {
+			t.Errorf("unexpected elimination %v", c)
+		}
+	}
+}
+
+func TestUniqueRectangleType3(t *testing.T) {
+	g := openGrid()
+	g.Cands[domain.CellIndex(0, 0)] = domain.DigitsOf(1, 2)
+	g.Cands[domain.CellIndex(0, 1)] = domain.DigitsOf(1, 2)
+	g.Cands[domain.CellIndex(3, 0)] = domain.DigitsOf(1, 2, 5)
+	g.Cands[domain.CellIndex(3, 1)] = domain.DigitsOf(1, 2, 6)
+	g.Cands[domain.CellIndex(3, 4)] = domain.DigitsOf(5, 6) // pairs with the roof's extras
+
+	hh, ok := uniqueRectangle(&g)
+	if !ok || hh.Technique != "unique rectangle type 3" {
+		t.Fatalf("hint = %+v", hh)
+	}
+	if len(hh.Eliminations) != 12 {
+		t.Errorf("eliminations = %v, want 5 and 6 from six cells of row 4", hh.Eliminations)
+	}
+	for _, c := range hh.Eliminations {
+		if c.Row != 3 || c.Col < 2 || c.Col == 4 || (c.Digit != 5 && c.Digit != 6) {
+			t.Errorf("unexpected elimination %v", c)
+		}
+	}
+}
+
+func TestBUGPlusOne(t *testing.T) {
+	// four {1,2} cells in two boxes are deadly, so r1c1 takes its extra 3
+	var g domain.Grid
+	for i := range g.Values {
+		g.Values[i] = 9
+	}
+	empty := func(r, c int, d ...uint8) {
+		g.Values[domain.CellIndex(r, c)] = 0
+		g.Cands[domain.CellIndex(r, c)] = domain.DigitsOf(d...)
+	}
+	empty(0, 0, 1, 2, 3)
+	empty(0, 1, 1, 2)
+	empty(3, 0, 1, 2)
+	empty(3, 1, 1, 2)
+
+	hh, ok := bugPlusOne(&g)
+	if !ok || hh.Technique != "BUG+1" || hh.Digit != 3 || len(hh.Cells) != 1 || hh.Cells[0] != (domain.CellCoord{Row: 0, Col: 0}) {
+		t.Fatalf("hint = %+v, ok=%v", hh, ok)
+	}
+
+	// every cell but one is still bivalue, and 1 is open three times in
+	// row 1, but columns 2 and 3 hold lone candidates, so there is no grave
+	g.Values[domain.CellIndex(3, 0)], g.Values[domain.CellIndex(3, 1)] = 9, 9
+	empty(0, 2, 1, 3)
+	if hh, ok := bugPlusOne(&g); ok {
+		t.Fatalf("BUG+1 without the full condition: %+v", hh)
+	}
+}
+
+func TestUniqueRectangleType4(t *testing.T) {
+	g := openGrid()
+	g.Cands[domain.CellIndex(0, 0)] = domain.DigitsOf(1, 2)
+	g.Cands[domain.CellIndex(0, 1)] = domain.DigitsOf(1, 2)
+	g.Cands[domain.CellIndex(3, 0)] = domain.DigitsOf(1, 2, 5, 6)
+	g.Cands[domain.CellIndex(3, 1)] = domain.DigitsOf(1, 2, 7, 8)
+	lockRow(&g, 1, 3, 0, 1) // 1 only fits in the roof within row 4
+
+	hh, ok := uniqueRectangle(&g)
+	if !ok || hh.Technique != "unique rectangle type 4" {
+		t.Fatalf("hint = %+v", hh)
+	}
+	want := []domain.Candidate{{Row: 3, Col: 0, Digit: 2}, {Row: 3, Col: 1, Digit: 2}}
+	if len(hh.Eliminations) != 2 || hh.Eliminations[0] != want[0] || hh.Eliminations[1] != want[1] {
+		t.Fatalf("eliminations = %v, want %v", hh.Eliminations, want)
+	}
+}
@@ -0,0 +1,174 @@
+package hint
+
+import (
+	"fmt"
+
+	"svw.info/sudoku/internal/domain"
+)
+
+// xyWing finds a bivalue pivot {x,y} seeing pincers {x,z} and {y,z}: one
+// pincer is z whatever the pivot holds, so cells seeing both lose z.
+func xyWing(g *domain.Grid) (domain.Hint, bool) {
+	for pivot := 0; pivot < 81; pivot++ {
+		pm := g.Cands[pivot]
+		if pm.Count() != 2 {
+			continue
+		}
+		peers := domain.Peers[pivot]
+		for i, p1 := range peers {
+			m1 := g.Cands[p1]
+			if m1.Count() != 2 || m1 == pm {
+				continue
+			}
+			for _, p2 := range peers[i+1:] {
+				m2 := g.Cands[p2]
+				if m2.Count() != 2 || m1^m2 != pm {
+					continue
+				}
+				z, ok := (m1 & m2).Single()
+				if !ok || pm.Has(z) {
+					continue
+				}
+				elims := eliminations(g, z, p1, p2)
+				if len(elims) == 0 {
+					continue
+				}
+				x, y := (m1 & pm).First(), (m2 & pm).First()
+				return domain.Hint{
+					Message: fmt.Sprintf("XY-Wing: pivot %s with pincers %s and %s; one pincer is %d, so remove %s",
+						cellName(pivot), cellName(p1), cellName(p2), z, elimText(elims)),
+					Cells:        coords(pivot, p1, p2),
+					Strategy:     domain.StrategyChains,
+					Technique:    "XY-Wing",
+					Eliminations: elims,
+					Links: []domain.Link{
+						strong(p1, z, p1, x),
+						weak(p1, x, pivot, x),
+						strong(pivot, x, pivot, y),
+						weak(pivot, y, p2, y),
+						strong(p2, y, p2, z),
+					},
+				}, true
+			}
+		}
+	}
+	return domain.Hint{}, false
+}
+
+// xyzWing finds a pivot {x,y,z} seeing pincers {x,z} and {y,z}: one of the
+// three is z, so cells seeing all of them lose z.
+func xyzWing(g *domain.Grid) (domain.Hint, bool) {
+	for pivot := 0; pivot < 81; pivot++ {
+		pm := g.Cands[pivot]
+		if pm.Count() != 3 {
+			continue
+		}
+		peers := domain.Peers[pivot]
+		for i, p1 := range peers {
+			m1 := g.Cands[p1]
+			if m1.Count() != 2 || m1&^pm != 0 {
+				continue
+			}
+			for _, p2 := range peers[i+1:] {
+				m2 := g.Cands[p2]
+				if m2.Count() != 2 || m2&^pm != 0 || m1|m2 != pm {
+					continue
+				}
+				z, ok := (m1 & m2).Single()
+				if !ok {
+					continue
+				}
+				elims := eliminations(g, z, pivot, p1, p2)
+				if len(elims) == 0 {
+					continue
+				}
+				x, y := m1.Without(z).First(), m2.Without(z).First()
+				return domain.Hint{
+					Message: fmt.Sprintf("XYZ-Wing: pivot %s with pincers %s and %s; one of them is %d, so remove %s",
+						cellName(pivot), cellName(p1), cellName(p2), z, elimText(elims)),
+					Cells:        coords(pivot, p1, p2),
+					Strategy:     domain.StrategyChains,
+					Technique:    "XYZ-Wing",
+					Eliminations: elims,
+					Links: []domain.Link{
+						strong(p1, z, p1, x),
+						weak(p1, x, pivot, x),
+						weak(pivot, y, p2, y),
+						strong(p2, y, p2, z),
+					},
+				}, true
+			}
+		}
+	}
+	return domain.Hint{}, false
+}
+
+// wWing finds two bivalue cells {x,y} joined through a strong link on x:
+// neither can be x without the other being x, so one of them is y.
+func wWing(g *domain.Grid) (domain.Hint, bool) {
+	for a := 0; a < 81; a++ {
+		am := g.Cands[a]
+		if am.Count() != 2 {
+			continue
+		}
+		for b := a + 1; b < 81; b++ {
+			if g.Cands[b] != am || sees(a, b) {
+				continue
+			}
+			for _, x := range am.List() {
+				y := am.Without(x).First()
+				elims := eliminations(g, y, a, b)
+				if len(elims) == 0 {
+					continue
+				}
+				for h := range domain.Houses {
+					c, d, ok := conjugate(g, h, x)
+					if !ok || c == a || c == b || d == a || d == b {
+						continue
+					}
+					if !(sees(c, a) && sees(d, b)) {
+						c, d = d, c
+					}
+					if !(sees(c, a) && sees(d, b)) {
+						continue
+					}
+					return domain.Hint{
+						Message: fmt.Sprintf("W-Wing: %s and %s are both %d/%d and %d is locked between %s and %s in %s; one of them is %d, so remove %s",
+							cellName(a), cellName(b), x, y, x, cellName(c), cellName(d), domain.HouseAt(h), y, elimText(elims)),
+						Cells:        coords(a, c, d, b),
+						Strategy:     domain.StrategyChains,
+						Technique:    "W-Wing",
+						Eliminations: elims,
+						Links: []domain.Link{
+							strong(a, y, a, x),
+							weak(a, x, c, x),
+							strong(c, x, d, x),
+							weak(d, x, b, x),
+							strong(b, x, b, y),
+						},
+					}, true
+				}
+			}
+		}
+	}
+	return domain.Hint{}, false
+}
+
+// conjugate returns the only two cells of house h that can hold v.
+func conjugate(g *domain.Grid, h int, v uint8) (a, b int, ok bool) {
+	a, b = -1, -1
+	for _, cell := range domain.Houses[h] {
+		if !g.Cands[cell].Has(v) {
+			continue
+		}
+		switch {
+		case a < 0:
+			a = cell
+		case b < 0:
+			b = cell
+		default:
+			return 0, 0, false
+		}
+	}
+	return a, b, b >= 0
+}
@@ -4,17 +4,30 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"svw.info/sudoku/internal/domain"
 )
 
-type FS struct{ dir string }
+type FS struct {
+	dir string
+	// Trash is how long deleted puzzles can be restored; zero deletes them
+	// at once.
+	Trash time.Duration
+}
 
 func NewFS(dir string) *FS { return &FS{dir: dir} }
 
+// notFound reports a missing puzzle or game. It still matches
+// os.ErrNotExist for callers that test for that.
+func notFound(what, id string) error {
+	return &domain.Error{Kind: domain.KindNotFound, Message: fmt.Sprintf("%s %q not found", what, id), Err: os.ErrNotExist}
+}
+
 func diffDir(d domain.Difficulty) string {
 	switch d {
 	case domain.Easy:
@@ -33,54 +46,88 @@ func (s *FS) pathFor(id string, d domain.Difficulty) string {
 	return filepath.Join(s.dir, sub, strings.TrimSpace(id)+".json")
 }
 
+// Save writes p to ./data/{difficulty}/{id}.json and removes any other copy
+// of it: under another difficulty, in the legacy flat layout or in the trash.
 func (s *FS) Save(ctx context.Context, p *domain.Puzzle) error {
 	if p == nil || p.ID == "" {
-		return errors.New("invalid puzzle: missing ID")
+		return domain.Errorf(domain.KindInvalid, "invalid puzzle: missing ID")
+	}
+	if strings.ContainsAny(p.ID, `/\`) {
+		return domain.Errorf(domain.KindInvalid, "invalid puzzle ID %q", p.ID)
 	}
 	// Ensure directory ./data/{difficulty} exists
 	target := s.pathFor(p.ID, p.Difficulty)
-	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+	if err := writeJSON(target, p); err != nil {
 		return err
 	}
-	f, err := os.Create(target)
+	for _, c := range s.candidates(p.ID) {
+		if c.path != target {
+			if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+	}
+	if err := os.Remove(s.trashPath(p.ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func writeJSON(path string, v any) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
 	enc := json.NewEncoder(f)
 	enc.SetIndent("", "  ")
-	return enc.Encode(p)
+	if err := enc.Encode(v); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
 }
 
-func (s *FS) Load(ctx context.Context, id string) (*domain.Puzzle, error) {
-	type cand struct {
-		path   string
-		diff   domain.Difficulty
-		legacy bool
-	}
-	candidates := []cand{
-		{filepath.Join(s.dir, "easy", id+".json"), domain.Easy, false},
-		{filepath.Join(s.dir, "medium", id+".json"), domain.Medium, false},
-		{filepath.Join(s.dir, "hard", id+".json"), domain.Hard, false},
-		{filepath.Join(s.dir, "expert", id+".json"), domain.Expert, false},
-		{filepath.Join(s.dir, id+".json"), 0, true}, // legacy flat layout
-	}
-	var chosen *cand
-	var data []byte
-	for i := range candidates {
-		c := candidates[i]
-		if _, statErr := os.Stat(c.path); statErr == nil {
-			b, err := os.ReadFile(c.path)
-			if err != nil {
-				return nil, err
+// candidate is a file a puzzle may be stored in.
+type candidate struct {
+	path   string
+	diff   domain.Difficulty
+	legacy bool
+}
+
+func (s *FS) candidates(id string) []candidate {
+	return []candidate{
+		{s.pathFor(id, domain.Easy), domain.Easy, false},
+		{s.pathFor(id, domain.Medium), domain.Medium, false},
+		{s.pathFor(id, domain.Hard), domain.Hard, false},
+		{s.pathFor(id, domain.Expert), domain.Expert, false},
+		{filepath.Join(s.dir, strings.TrimSpace(id)+".json"), 0, true}, // legacy flat layout
+	}
+}
+
+// locate finds the file of puzzle id. IDs never contain path separators, so
+// one that does cannot reach outside the store.
+func (s *FS) locate(id string) (candidate, error) {
+	if strings.TrimSpace(id) != "" && !strings.ContainsAny(id, `/\`) {
+		for _, c := range s.candidates(id) {
+			if _, err := os.Stat(c.path); err == nil {
+				return c, nil
 			}
-			data = b
-			chosen = &c
-			break
 		}
 	}
-	if data == nil {
-		return nil, os.ErrNotExist
+	return candidate{}, notFound("puzzle", id)
+}
+
+func (s *FS) Load(ctx context.Context, id string) (*domain.Puzzle, error) {
+	c, err := s.locate(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return nil, err
 	}
 	var out domain.Puzzle
 	if err := json.Unmarshal(data, &out); err != nil {
@@ -88,8 +135,8 @@ func (s *FS) Load(ctx context.Context, id string) (*domain.Puzzle, error) {
 	}
 	// If difficulty missing, infer from the folder we loaded from (legacy defaults to Medium)
 	if out.Difficulty == 0 {
-		if chosen != nil && !chosen.legacy {
-			out.Difficulty = chosen.diff
+		if !c.legacy {
+			out.Difficulty = c.diff
 		} else {
 			out.Difficulty = domain.Medium
 		}
@@ -97,12 +144,104 @@ func (s *FS) Load(ctx context.Context, id string) (*domain.Puzzle, error) {
 	return &out, nil
 }
 
+// Update applies patch to a stored puzzle. A puzzle in the legacy flat
+// layout moves into the folder of its difficulty.
+func (s *FS) Update(ctx context.Context, id string, patch domain.PuzzlePatch) (*domain.Puzzle, error) {
+	p, err := s.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	patch.Apply(p)
+	if err := s.Save(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *FS) trashPath(id string) string {
+	return filepath.Join(s.dir, "trash", strings.TrimSpace(id)+".json")
+}
+
+// Delete removes a stored puzzle. With a Trash period it goes to
+// ./data/trash/{id}.json first, where the file's modification time records
+// when it was deleted.
+func (s *FS) Delete(ctx context.Context, id string) (time.Time, error) {
+	c, err := s.locate(id)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if s.Trash <= 0 {
+		return time.Time{}, os.Remove(c.path)
+	}
+	// Trashed files are complete puzzles, with the difficulty their folder
+	// implied, so Restore need not know where they came from.
+	p, err := s.Load(ctx, id)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err := writeJSON(s.trashPath(id), p); err != nil {
+		return time.Time{}, err
+	}
+	if err := os.Remove(c.path); err != nil {
+		return time.Time{}, err
+	}
+	s.purge()
+	return time.Now().Add(s.Trash), nil
+}
+
+// Restore brings a deleted puzzle back from the trash, unless its Trash
+// period has run out.
+func (s *FS) Restore(ctx context.Context, id string) (*domain.Puzzle, error) {
+	s.purge()
+	if strings.TrimSpace(id) == "" || strings.ContainsAny(id, `/\`) {
+		return nil, notFound("deleted puzzle", id)
+	}
+	data, err := os.ReadFile(s.trashPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, notFound("deleted puzzle", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.locate(id); err == nil {
+		return nil, domain.Errorf(domain.KindConflict, "puzzle %q exists again", id)
+	}
+	var p domain.Puzzle
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	if err := s.Save(ctx, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// purge removes trashed puzzles deleted longer than Trash ago. Errors are
+// ignored: the files are gone for clients either way and the next purge
+// tries again.
+func (s *FS) purge() {
+	dir := filepath.Join(s.dir, "trash")
+	ents, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range ents {
+		if info, err := e.Info(); err == nil && time.Since(info.ModTime()) > s.Trash {
+			_ = os.Remove(filepath.Join(dir, e.Name()))
+		}
+	}
+}
+
 func (s *FS) List(ctx context.Context) ([]domain.PuzzleMeta, error) {
 	type m struct {
 		ID         string            `json:"id"`
 		Name       string            `json:"name,omitempty"`
 		Difficulty domain.Difficulty `json:"difficulty"`
 		CreatedAt  int64             `json:"createdAt"`
+		Completed  bool              `json:"completed,omitempty"`
+		Collection string            `json:"collection,omitempty"`
+		Tags       []string          `json:"tags,omitempty"`
+		Favorite   bool              `json:"favorite,omitempty"`
 	}
 
 	var out []domain.PuzzleMeta
@@ -151,6 +290,10 @@ func (s *FS) List(ctx context.Context) ([]domain.PuzzleMeta, error) {
 				Name:       mm.Name,
 				Difficulty: dd,
 				CreatedAt:  mm.CreatedAt,
+				Completed:  mm.Completed,
+				Collection: mm.Collection,
+				Tags:       mm.Tags,
+				Favorite:   mm.Favorite,
 			})
 		}
 	}
@@ -182,8 +325,43 @@ func (s *FS) List(ctx context.Context) ([]domain.PuzzleMeta, error) {
 				Name:       mm.Name,
 				Difficulty: dd,
 				CreatedAt:  mm.CreatedAt,
+				Completed:  mm.Completed,
+				Collection: mm.Collection,
+				Tags:       mm.Tags,
+				Favorite:   mm.Favorite,
 			})
 		}
 	}
 	return out, nil
-}
\ No newline at end of file
+}
+
+func (s *FS) gamePath(id string) string {
+	return filepath.Join(s.dir, "games", strings.TrimSpace(id)+".json")
+}
+
+// SaveGame writes a game session to ./data/games/{id}.json.
+func (s *FS) SaveGame(ctx context.Context, g *domain.Game) error {
+	if g == nil || g.ID == "" {
+		return domain.Errorf(domain.KindInvalid, "invalid game: missing ID")
+	}
+	target := s.gamePath(g.ID)
+	return writeJSON(target, g)
+}
+
+func (s *FS) LoadGame(ctx context.Context, id string) (*domain.Game, error) {
+	if strings.ContainsAny(id, `/\`) {
+		return nil, notFound("game", id)
+	}
+	data, err := os.ReadFile(s.gamePath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, notFound("game", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out domain.Game
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
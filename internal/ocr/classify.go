@@ -0,0 +1,196 @@
+package ocr
+
+import (
+	"image"
+	"math"
+	"sync"
+
+	"svw.info/sudoku/internal/render"
+)
+
+// Glyphs are compared at sw×sh, keeping their aspect ratio.
+const (
+	sw, sh = 16, 24
+
+	holePenalty = 0.3  // subtracted from the score of a template with other holes
+	temperature = 0.04 // softness of the confidence over template scores
+)
+
+// sample is a glyph normalised for comparison.
+type sample struct {
+	vec   [sw * sh]float64 // blurred coverage, zero mean, unit length
+	holes int              // enclosed background regions, like the two of 8
+	holeY float64          // height of the hole's centre, 0 top to 1 bottom, when there is one
+}
+
+// newSample stretches the ink within r to fill sw×sh, averaging 4×4 points
+// per pixel, which evens out the widths of different fonts. Narrow glyphs,
+// the 1s, keep their aspect ratio and are centred instead.
+func newSample(ink func(x, y int) bool, r image.Rectangle) sample {
+	const sub = 4
+	sx, sy := float64(sw)/float64(r.Dx()), float64(sh)/float64(r.Dy())
+	if r.Dx()*5 < r.Dy()*2 {
+		sx = sy
+	}
+	ox := (sw - sx*float64(r.Dx())) / 2
+	var cov [sw * sh]float64
+	for ty := 0; ty < sh; ty++ {
+		for tx := 0; tx < sw; tx++ {
+			n := 0
+			for j := 0; j < sub; j++ {
+				for i := 0; i < sub; i++ {
+					px := (float64(tx) + (float64(i)+0.5)/sub - ox) / sx
+					py := (float64(ty) + (float64(j)+0.5)/sub) / sy
+					if px >= 0 && py >= 0 && px < float64(r.Dx()) && py < float64(r.Dy()) && ink(r.Min.X+int(px), r.Min.Y+int(py)) {
+						n++
+					}
+				}
+			}
+			cov[ty*sw+tx] = float64(n) / (sub * sub)
+		}
+	}
+
+	var s sample
+	s.holes, s.holeY = holes(ink, r)
+	mean := 0.0
+	for y := 0; y < sh; y++ {
+		for x := 0; x < sw; x++ {
+			sum, n := 0.0, 0
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					if nx, ny := x+dx, y+dy; nx >= 0 && ny >= 0 && nx < sw && ny < sh {
+						sum += cov[ny*sw+nx]
+						n++
+					}
+				}
+			}
+			s.vec[y*sw+x] = sum / float64(n)
+			mean += s.vec[y*sw+x]
+		}
+	}
+	mean /= sw * sh
+	norm := 0.0
+	for i := range s.vec {
+		s.vec[i] -= mean
+		norm += s.vec[i] * s.vec[i]
+	}
+	if norm = math.Sqrt(norm); norm > 0 {
+		for i := range s.vec {
+			s.vec[i] /= norm
+		}
+	}
+	return s
+}
+
+// holes counts the 4-connected background regions within r that do not
+// reach its edge, ignoring specks under 1/200 of its area, and returns the
+// relative height of the centre of the largest.
+func holes(ink func(x, y int) bool, r image.Rectangle) (int, float64) {
+	w, h := r.Dx(), r.Dy()
+	seen := make([]bool, w*h)
+	count, bestN, bestY := 0, 0, 0.0
+	var stack []int
+	for start := range seen {
+		if seen[start] || ink(r.Min.X+start%w, r.Min.Y+start/w) {
+			continue
+		}
+		seen[start] = true
+		stack = append(stack[:0], start)
+		n, sumY, open := 0, 0, false
+		for len(stack) > 0 {
+			i := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			x, y := i%w, i/w
+			n++
+			sumY += y
+			if x == 0 || y == 0 || x == w-1 || y == h-1 {
+				open = true
+			}
+			for _, d := range [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+				nx, ny := x+d[0], y+d[1]
+				if nx < 0 || ny < 0 || nx >= w || ny >= h {
+					continue
+				}
+				if j := ny*w + nx; !seen[j] && !ink(r.Min.X+nx, r.Min.Y+ny) {
+					seen[j] = true
+					stack = append(stack, j)
+				}
+			}
+		}
+		if open || n*200 < w*h {
+			continue
+		}
+		count++
+		if n > bestN {
+			bestN, bestY = n, (float64(sumY)/float64(n)+0.5)/float64(h)
+		}
+	}
+	return count, bestY
+}
+
+// score is the correlation of s with template t, less holePenalty when s
+// has more holes, a third of it when fewer, and part of it as a single hole
+// sits higher or lower.
+func (s *sample) score(t *sample) float64 {
+	corr := 0.0
+	for i := range s.vec {
+		corr += s.vec[i] * t.vec[i]
+	}
+	switch {
+	case s.holes < t.holes:
+		corr -= holePenalty / 3 // heavy print fills small holes in
+	case s.holes > t.holes:
+		corr -= holePenalty
+	case s.holes == 1:
+		corr -= holePenalty * math.Min(1, math.Abs(s.holeY-t.holeY)/0.2)
+	}
+	return corr
+}
+
+// templates are the stroke-font digits in a light, regular and bold
+// weight, indexed by digit. Heavy strokes can close small holes, so all
+// weights take their holes from the light one.
+var templates = sync.OnceValue(func() [10][]sample {
+	var ts [10][]sample
+	for d := uint8(1); d <= 9; d++ {
+		for _, weight := range []float64{0.07, 0.11, 0.15} {
+			m := render.Glyph(d, 48, 72, weight)
+			ink := func(x, y int) bool { return m.AlphaAt(x, y).A >= 128 }
+			var r image.Rectangle
+			for y := 0; y < 72; y++ {
+				for x := 0; x < 48; x++ {
+					if ink(x, y) {
+						r = r.Union(image.Rect(x, y, x+1, y+1))
+					}
+				}
+			}
+			s := newSample(ink, r)
+			if len(ts[d]) > 0 {
+				s.holes, s.holeY = ts[d][0].holes, ts[d][0].holeY
+			}
+			ts[d] = append(ts[d], s)
+		}
+	}
+	return ts
+})
+
+// classify returns the digit whose templates match s best, with a
+// confidence from a softmax over every digit's best score.
+func classify(s *sample) (uint8, float64) {
+	var scores [10]float64
+	best := uint8(1)
+	for d := uint8(1); d <= 9; d++ {
+		scores[d] = math.Inf(-1)
+		for i := range templates()[d] {
+			scores[d] = math.Max(scores[d], s.score(&templates()[d][i]))
+		}
+		if scores[d] > scores[best] {
+			best = d
+		}
+	}
+	sum := 0.0
+	for d := 1; d <= 9; d++ {
+		sum += math.Exp((scores[d] - scores[best]) / temperature)
+	}
+	return best, 1 / sum
+}
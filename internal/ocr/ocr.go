@@ -0,0 +1,318 @@
+// Package ocr reads a sudoku from a screenshot. It finds the grid in a
+// clean, axis-aligned image, splits it into 81 cells and recognises printed
+// digits by comparing each with templates drawn in the render package's
+// stroke font. Only the standard library is used.
+package ocr
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // registered for Decode
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"math"
+
+	"svw.info/sudoku/internal/domain"
+)
+
+// Errors for input that cannot be read.
+var (
+	ErrImage  = domain.Errorf(domain.KindInvalid, "unreadable image")
+	ErrNoGrid = domain.Errorf(domain.KindInvalid, "no sudoku grid found in the image")
+)
+
+// Uncertain is the confidence below which a cell deserves a second look.
+const Uncertain = 0.8
+
+const (
+	maxSide   = 1600       // larger images are scaled down first
+	maxPixels = 25_000_000 // larger images are refused before decoding
+	minCell   = 12         // pixels per cell below which digits are unreadable
+	inset     = 0.12       // fraction of a cell trimmed on each side to drop grid lines
+)
+
+// Result is a recognised board. Digits are marked Fixed, since everything
+// printed in a screenshot counts as a given.
+type Result struct {
+	Board domain.Board
+	// Confidence is how sure the reading of each cell is, empty or not,
+	// from 0 to 1 rounded to two decimals.
+	Confidence [9][9]float64
+	Grid       image.Rectangle // where the grid was found
+}
+
+// Decode reads a PNG, JPEG or GIF image and recognises the board in it.
+// The header is checked first, since a small file can declare dimensions
+// whose pixels would not fit in memory.
+func Decode(r io.Reader) (*Result, error) {
+	var head bytes.Buffer
+	cfg, _, err := image.DecodeConfig(io.TeeReader(r, &head))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImage, err)
+	}
+	if px := int64(cfg.Width) * int64(cfg.Height); px > maxPixels {
+		return nil, fmt.Errorf("%w: %d×%d pixels, more than %d million", ErrImage, cfg.Width, cfg.Height, maxPixels/1_000_000)
+	}
+	img, _, err := image.Decode(io.MultiReader(&head, r))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImage, err)
+	}
+	return Read(img)
+}
+
+// Read recognises the board in img. The grid is the largest square-ish
+// connected structure of lines with box borders at its thirds; cells are
+// then read one by one against their own background, so highlighted cells,
+// dark themes and coloured digits work alike. Small pencil marks are
+// ignored.
+func Read(img image.Image) (*Result, error) {
+	g, scale := toGray(img)
+	t, lo, hi := otsu(g.pix)
+	dark := 0
+	for _, v := range g.pix {
+		if v <= t {
+			dark++
+		}
+	}
+	inkIsDark := 2*dark <= len(g.pix)
+	ink := make([]bool, len(g.pix))
+	for i, v := range g.pix {
+		ink[i] = (v <= t) == inkIsDark
+	}
+	grid, ok := findGrid(ink, g.w, g.h)
+	if !ok {
+		return nil, ErrNoGrid
+	}
+
+	res := &Result{Grid: image.Rect(grid.Min.X*scale, grid.Min.Y*scale, grid.Max.X*scale, grid.Max.Y*scale)}
+	delta := math.Max(20, 0.25*(hi-lo))
+	cw, ch := float64(grid.Dx())/9, float64(grid.Dy())/9
+	for r := 0; r < 9; r++ {
+		for c := 0; c < 9; c++ {
+			x0, y0 := float64(grid.Min.X)+float64(c)*cw, float64(grid.Min.Y)+float64(r)*ch
+			cell := image.Rect(int(x0+inset*cw), int(y0+inset*ch), int(x0+(1-inset)*cw), int(y0+(1-inset)*ch))
+			d, conf := g.readCell(cell, math.Min(cw, ch), delta)
+			res.Board.Values[r][c], res.Board.Fixed[r][c] = d, d != 0
+			res.Confidence[r][c] = math.Round(conf*100) / 100
+		}
+	}
+	return res, nil
+}
+
+// gray is an 8-bit luminance image.
+type gray struct {
+	w, h int
+	pix  []uint8
+}
+
+func (g *gray) at(x, y int) uint8 { return g.pix[y*g.w+x] }
+
+// toGray converts img to luminance over a white background, averaging it
+// down by an integer factor, which it returns, when it exceeds maxSide.
+func toGray(img image.Image) (*gray, int) {
+	b := img.Bounds()
+	k := max(1, (max(b.Dx(), b.Dy())+maxSide-1)/maxSide)
+	g := &gray{w: b.Dx() / k, h: b.Dy() / k}
+	g.pix = make([]uint8, g.w*g.h)
+	for y := 0; y < g.h; y++ {
+		for x := 0; x < g.w; x++ {
+			sum := 0
+			for dy := 0; dy < k; dy++ {
+				for dx := 0; dx < k; dx++ {
+					sum += luma(img.At(b.Min.X+x*k+dx, b.Min.Y+y*k+dy))
+				}
+			}
+			g.pix[y*g.w+x] = uint8(sum / (k * k))
+		}
+	}
+	return g, k
+}
+
+// luma is the luminance of c composited over white, with the weights of
+// color.GrayModel.
+func luma(c color.Color) int {
+	r, g, b, a := c.RGBA()
+	r, g, b = r+0xffff-a, g+0xffff-a, b+0xffff-a
+	return int((19595*r + 38470*g + 7471*b + 1<<15) >> 24)
+}
+
+// otsu returns the threshold that best separates pix into two classes and
+// the mean level of each.
+func otsu(pix []uint8) (t uint8, lo, hi float64) {
+	var hist [256]int
+	for _, v := range pix {
+		hist[v]++
+	}
+	total, sum := float64(len(pix)), 0.0
+	for i, n := range hist {
+		sum += float64(i * n)
+	}
+	var w0, sum0, best float64
+	for i, n := range hist {
+		w0 += float64(n)
+		sum0 += float64(i * n)
+		w1 := total - w0
+		if w0 == 0 || w1 == 0 {
+			continue
+		}
+		m0, m1 := sum0/w0, (sum-sum0)/w1
+		if v := w0 * w1 * (m0 - m1) * (m0 - m1); v > best {
+			best, t, lo, hi = v, uint8(i), m0, m1
+		}
+	}
+	return t, lo, hi
+}
+
+// comp is a connected component of a binary image.
+type comp struct {
+	r image.Rectangle
+	n int
+}
+
+// components labels the 8-connected regions of ink, a w×h image. Labels
+// are indices into the returned slice plus one; 0 is background.
+func components(ink []bool, w, h int) ([]int32, []comp) {
+	labels := make([]int32, len(ink))
+	var comps []comp
+	var stack []int
+	for start, on := range ink {
+		if !on || labels[start] != 0 {
+			continue
+		}
+		id := int32(len(comps) + 1)
+		c := comp{r: image.Rect(start%w, start/w, start%w+1, start/w+1)}
+		labels[start] = id
+		stack = append(stack[:0], start)
+		for len(stack) > 0 {
+			i := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			x, y := i%w, i/w
+			c.n++
+			c.r = c.r.Union(image.Rect(x, y, x+1, y+1))
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					nx, ny := x+dx, y+dy
+					if nx < 0 || ny < 0 || nx >= w || ny >= h {
+						continue
+					}
+					if j := ny*w + nx; ink[j] && labels[j] == 0 {
+						labels[j] = id
+						stack = append(stack, j)
+					}
+				}
+			}
+		}
+		comps = append(comps, c)
+	}
+	return labels, comps
+}
+
+// findGrid picks the largest component that is roughly square, mostly
+// empty inside and crossed by box borders at a third and two thirds of its
+// height and width.
+func findGrid(ink []bool, w, h int) (image.Rectangle, bool) {
+	labels, comps := components(ink, w, h)
+	var best image.Rectangle
+	for i, c := range comps {
+		dx, dy := c.r.Dx(), c.r.Dy()
+		if dx < 9*minCell || dy < 9*minCell || dx*4 < dy*3 || dy*4 < dx*3 {
+			continue
+		}
+		if area := dx * dy; float64(c.n) > 0.5*float64(area) || area <= best.Dx()*best.Dy() {
+			continue
+		}
+		if hasBoxLines(labels, w, int32(i+1), c.r) {
+			best = c.r
+		}
+	}
+	return best, !best.Empty()
+}
+
+func hasBoxLines(labels []int32, w int, id int32, r image.Rectangle) bool {
+	// line reports whether some row (or column) within a quarter cell of
+	// pos is mostly made of the component.
+	line := func(pos, n, length int, horizontal bool) bool {
+		for p := pos - n; p <= pos+n; p++ {
+			count := 0
+			for q := 0; q < length; q++ {
+				x, y := r.Min.X+q, p
+				if !horizontal {
+					x, y = p, r.Min.Y+q
+				}
+				if labels[y*w+x] == id {
+					count++
+				}
+			}
+			if count*10 >= length*8 {
+				return true
+			}
+		}
+		return false
+	}
+	for k := 1; k <= 2; k++ {
+		if !line(r.Min.Y+k*r.Dy()/3, r.Dy()/36, r.Dx(), true) ||
+			!line(r.Min.X+k*r.Dx()/3, r.Dx()/36, r.Dy(), false) {
+			return false
+		}
+	}
+	return true
+}
+
+// readCell recognises the digit inside cell, the inner part of a cell of
+// edge size. Ink is whatever differs from the cell's median level by more
+// than delta. Components reaching the edge of the area while thinner than
+// a fifth of the cell are left-over grid lines; components shorter than
+// 0.3 of the cell are pencil marks or noise.
+func (g *gray) readCell(cell image.Rectangle, size, delta float64) (uint8, float64) {
+	var hist [256]int
+	for y := cell.Min.Y; y < cell.Max.Y; y++ {
+		for x := cell.Min.X; x < cell.Max.X; x++ {
+			hist[g.at(x, y)]++
+		}
+	}
+	bg := 0 // the median level
+	for seen := hist[0]; seen*2 < cell.Dx()*cell.Dy(); seen += hist[bg] {
+		bg++
+	}
+	w, h := cell.Dx(), cell.Dy()
+	ink := make([]bool, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			ink[y*w+x] = math.Abs(float64(g.at(cell.Min.X+x, cell.Min.Y+y))-float64(bg)) > delta
+		}
+	}
+	labels, comps := components(ink, w, h)
+	keep := make([]bool, len(comps)+1)
+	var glyph image.Rectangle
+	for i, c := range comps {
+		edge := c.r.Min.X == 0 || c.r.Min.Y == 0 || c.r.Max.X == w || c.r.Max.Y == h
+		if edge && float64(min(c.r.Dx(), c.r.Dy())) < 0.2*size {
+			comps[i].n = 0 // a line remnant
+			continue
+		}
+		if float64(c.r.Dy()) >= 0.3*size {
+			keep[i+1] = true
+			glyph = glyph.Union(c.r)
+		}
+	}
+	if glyph.Empty() {
+		return 0, 1
+	}
+	// Faint strokes can break a glyph apart; take in pieces touching it.
+	gap := int(0.05*size) + 1
+	for grown := true; grown; {
+		grown = false
+		near := glyph.Inset(-gap)
+		for i, c := range comps {
+			if c.n > 0 && !keep[i+1] && c.r.Overlaps(near) {
+				keep[i+1], grown = true, true
+				glyph = glyph.Union(c.r)
+			}
+		}
+	}
+	s := newSample(func(x, y int) bool { return keep[labels[y*w+x]] }, glyph)
+	return classify(&s)
+}
@@ -0,0 +1,141 @@
+package ocr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/draw"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/render"
+)
+
+var puzzle = [9][9]uint8{
+	{5, 3, 0, 0, 7, 0, 0, 0, 0},
+	{6, 0, 0, 1, 9, 5, 0, 0, 0},
+	{0, 9, 8, 0, 0, 0, 0, 6, 0},
+	{8, 0, 0, 0, 6, 0, 0, 0, 3},
+	{4, 0, 0, 8, 0, 3, 0, 0, 1},
+	{7, 0, 0, 0, 2, 0, 0, 0, 6},
+	{0, 6, 0, 0, 0, 0, 2, 8, 0},
+	{0, 0, 0, 4, 1, 9, 0, 0, 5},
+	{0, 0, 0, 0, 8, 0, 0, 7, 9},
+}
+
+func TestRead(t *testing.T) {
+	b := domain.Board{Values: puzzle}
+	var marks domain.Marks
+	marks[0][2] = domain.Digits(0).With(1).With(2).With(4)
+	for _, tc := range []struct {
+		name  string
+		theme string
+		size  int
+	}{
+		{"light", "light", 450},
+		{"dark", "dark", 450},
+		{"small", "print", 180},
+		{"large", "light", 1800},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			img, err := render.Image(&b, render.Options{Size: tc.size, Theme: tc.theme, Marks: &marks})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Frame the board like a screenshot of an app.
+			shot := image.NewRGBA(image.Rect(0, 0, tc.size+80, tc.size+300))
+			draw.Draw(shot, shot.Bounds(), image.NewUniform(img.At(0, 0)), image.Point{}, draw.Src)
+			draw.Draw(shot, image.Rect(0, 0, tc.size+80, 60), image.NewUniform(color.RGBA{0x33, 0x44, 0x99, 0xff}), image.Point{}, draw.Src)
+			draw.Draw(shot, img.Bounds().Add(image.Pt(40, 120)), img, image.Point{}, draw.Src)
+
+			res, err := Read(shot)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Board.Values != puzzle {
+				t.Fatalf("read\n%v\nwant\n%v", res.Board.Values, puzzle)
+			}
+			for r := 0; r < 9; r++ {
+				for c := 0; c < 9; c++ {
+					if res.Confidence[r][c] < Uncertain {
+						t.Errorf("r%dc%d: confidence %.2f", r+1, c+1, res.Confidence[r][c])
+					}
+					if res.Board.Fixed[r][c] != (puzzle[r][c] != 0) {
+						t.Errorf("r%dc%d: fixed %v", r+1, c+1, res.Board.Fixed[r][c])
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestReadTypefaces reads screenshots whose digits are set in real fonts
+// rather than the stroke font the templates are drawn in: DejaVu Sans at 44
+// pixels a cell and DejaVu Serif Bold at 30, each on an app-like page with
+// a title bar and a highlighted cell.
+func TestReadTypefaces(t *testing.T) {
+	want := [9][9]uint8{
+		{0, 0, 3, 0, 2, 0, 6, 0, 0},
+		{9, 0, 0, 3, 0, 5, 0, 0, 1},
+		{0, 0, 1, 8, 0, 6, 4, 0, 0},
+		{0, 0, 8, 1, 0, 2, 9, 0, 0},
+		{7, 0, 0, 0, 0, 0, 0, 0, 8},
+		{0, 0, 6, 7, 0, 8, 2, 0, 0},
+		{0, 0, 2, 6, 0, 9, 5, 0, 0},
+		{8, 0, 0, 2, 0, 3, 0, 0, 9},
+		{0, 0, 5, 0, 1, 0, 3, 0, 0},
+	}
+	for _, name := range []string{"dejavu-sans.png", "dejavu-serif-bold.png"} {
+		t.Run(name, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			res, err := Decode(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Board.Values != want {
+				t.Fatalf("read\n%v\nwant\n%v", res.Board.Values, want)
+			}
+			for r := 0; r < 9; r++ {
+				for c := 0; c < 9; c++ {
+					if res.Confidence[r][c] < Uncertain {
+						t.Errorf("r%dc%d: confidence %.2f", r+1, c+1, res.Confidence[r][c])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeNoGrid(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 300, 300))
+	if _, err := Read(img); !errors.Is(err, ErrNoGrid) {
+		t.Errorf("blank image: err = %v", err)
+	}
+	if _, err := Decode(bytes.NewReader([]byte("not an image"))); err == nil {
+		t.Error("expected a decode error")
+	}
+}
+
+func TestDecodeTooLarge(t *testing.T) {
+	// A PNG header declaring 40000×40000 pixels, with no pixel data behind it.
+	ihdr := []byte("IHDR\x00\x00\x9c\x40\x00\x00\x9c\x40\x08\x00\x00\x00\x00")
+	var png bytes.Buffer
+	png.WriteString("\x89PNG\r\n\x1a\n")
+	_ = binary.Write(&png, binary.BigEndian, uint32(len(ihdr)-4))
+	png.Write(ihdr)
+	_ = binary.Write(&png, binary.BigEndian, crc32.ChecksumIEEE(ihdr))
+	_, err := Decode(&png)
+	if !errors.Is(err, ErrImage) || !strings.Contains(err.Error(), "40000×40000") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+package pdf
+
+import (
+	"fmt"
+	"io"
+
+	"svw.info/sudoku/internal/domain"
+)
+
+// Entry is one puzzle of a booklet. A nil Solution leaves it out of the
+// answer key.
+type Entry struct {
+	Puzzle   domain.Puzzle
+	Solution *domain.Board
+}
+
+// Options controls the booklet layout.
+type Options struct {
+	Title      string // heading of every page and the document title
+	PerPage    int    // puzzles per page, 1..MaxPerPage; 0 means 4
+	Candidates bool   // pencil the candidates into empty cells
+}
+
+// MaxPerPage is the most puzzles that still print legibly on one page.
+const MaxPerPage = 12
+
+const (
+	margin         = 40.0
+	headerHeight   = 30.0
+	footerHeight   = 20.0
+	labelHeight    = 16.0
+	answersPerPage = 12
+	capHeight      = 0.718 // Helvetica cap height as a fraction of the font size
+)
+
+// Booklet lays out the puzzles PerPage to a page, each under a label with
+// its number, name and difficulty, followed by an answer key of the
+// solutions twelve to a page. Givens are the puzzle's fixed cells, or all
+// its values when none are marked fixed; player entries are not printed.
+func Booklet(w io.Writer, entries []Entry, opt Options) error {
+	if len(entries) == 0 {
+		return domain.Errorf(domain.KindNotFound, "no puzzles to print")
+	}
+	per := opt.PerPage
+	if per <= 0 {
+		per = 4
+	}
+	per = min(per, MaxPerPage)
+	doc := New(A4Width, A4Height)
+	doc.Title = opt.Title
+
+	for start := 0; start < len(entries); start += per {
+		pg := doc.AddPage()
+		pageFrame(doc, pg, opt.Title)
+		for i, s := range slots(per) {
+			n := start + i
+			if n >= len(entries) {
+				break
+			}
+			e := &entries[n]
+			givens := e.Puzzle.Givens()
+			var cands *domain.Grid
+			if opt.Candidates {
+				g, _ := domain.NewGrid(&givens)
+				cands = &g
+			}
+			drawEntry(pg, s, label(n, &e.Puzzle), &givens, &givens, cands)
+		}
+	}
+
+	var key []int
+	for i := range entries {
+		if entries[i].Solution != nil {
+			key = append(key, i)
+		}
+	}
+	heading := "Answers"
+	if opt.Title != "" {
+		heading = opt.Title + " - Answers"
+	}
+	for start := 0; start < len(key); start += answersPerPage {
+		pg := doc.AddPage()
+		pageFrame(doc, pg, heading)
+		for i, s := range slots(answersPerPage) {
+			if start+i >= len(key) {
+				break
+			}
+			n := key[start+i]
+			e := &entries[n]
+			givens := e.Puzzle.Givens()
+			drawEntry(pg, s, label(n, &e.Puzzle), e.Solution, &givens, nil)
+		}
+	}
+	return doc.Write(w)
+}
+
+// label reads like "3. Weekly club - hard", or "Puzzle 3 - hard" for a
+// puzzle without a name.
+func label(n int, p *domain.Puzzle) string {
+	name := fmt.Sprintf("Puzzle %d", n+1)
+	if p.Name != "" {
+		name = fmt.Sprintf("%d. %s", n+1, p.Name)
+	}
+	return name + " - " + p.Difficulty.String()
+}
+
+// pageFrame draws the heading and the page number.
+func pageFrame(doc *Doc, pg *Page, heading string) {
+	if heading != "" {
+		pg.Text(margin, doc.H-margin-16, 16, true, heading)
+	}
+	no := fmt.Sprint(doc.Pages())
+	const size = 10
+	pg.Text((doc.W-DigitWidth*size*float64(len(no)))/2, margin-size, size, false, no)
+}
+
+type slot struct{ x, y, w, h float64 } // y is the bottom edge
+
+// slots divides the area between heading and footer into n cells: one
+// column for up to two puzzles, two for up to six, three beyond.
+func slots(n int) []slot {
+	cols := 3
+	switch {
+	case n <= 2:
+		cols = 1
+	case n <= 6:
+		cols = 2
+	}
+	rows := (n + cols - 1) / cols
+	top := A4Height - margin - headerHeight
+	bottom := margin + footerHeight
+	w := (A4Width - 2*margin) / float64(cols)
+	h := (top - bottom) / float64(rows)
+	out := make([]slot, 0, n)
+	for i := 0; i < n; i++ {
+		r, c := i/cols, i%cols
+		out = append(out, slot{margin + float64(c)*w, top - float64(r+1)*h, w, h})
+	}
+	return out
+}
+
+// drawEntry prints the label and a grid of b, centred in s. Cells given in
+// givens are set in bold; cands, when set, fills the empty cells.
+func drawEntry(pg *Page, s slot, text string, b, givens *domain.Board, cands *domain.Grid) {
+	const pad = 10.0
+	size := min(s.w, s.h-labelHeight) - pad
+	x := s.x + (s.w-size)/2
+	top := s.y + s.h - labelHeight
+	pg.Text(x, top+4, min(11, labelHeight*0.7), true, text)
+	drawGrid(pg, x, top-size-2, size, b, givens, cands)
+}
+
+// drawGrid draws the board with its bottom-left corner at (x, y).
+func drawGrid(pg *Page, x, y, size float64, b, givens *domain.Board, cands *domain.Grid) {
+	cell := size / 9
+	for i := 0; i <= 9; i++ {
+		width := 0.5
+		if i%3 == 0 {
+			width = 2
+		}
+		off := float64(i) * cell
+		pg.Line(x+off, y, x+off, y+size, width)
+		pg.Line(x, y+off, x+size, y+off, width)
+	}
+	fs := cell * 0.62
+	small := cell * 0.24
+	for r := 0; r < 9; r++ {
+		for c := 0; c < 9; c++ {
+			cx, cy := x+float64(c)*cell, y+float64(8-r)*cell
+			if v := b.Values[r][c]; v != 0 {
+				given := givens.Values[r][c] != 0
+				if !given {
+					pg.Gray(0.3)
+				}
+				pg.Text(cx+(cell-DigitWidth*fs)/2, cy+(cell-capHeight*fs)/2, fs, given, fmt.Sprint(v))
+				if !given {
+					pg.Gray(0)
+				}
+				continue
+			}
+			if cands == nil {
+				continue
+			}
+			pg.Gray(0.35)
+			sub := cell / 3
+			for _, v := range cands.Cands[r*9+c].List() {
+				i, j := float64((v-1)%3), float64(2-(v-1)/3)
+				pg.Text(cx+i*sub+(sub-DigitWidth*small)/2, cy+j*sub+(sub-capHeight*small)/2, small, false, fmt.Sprint(v))
+			}
+			pg.Gray(0)
+		}
+	}
+}
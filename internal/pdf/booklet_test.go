@@ -0,0 +1,89 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"svw.info/sudoku/internal/domain"
+)
+
+// checkXref verifies that every xref entry points at its object.
+func checkXref(t *testing.T, pdf []byte) {
+	t.Helper()
+	i := bytes.LastIndex(pdf, []byte("startxref\n"))
+	var start int
+	fmt.Sscan(string(pdf[i+len("startxref\n"):]), &start)
+	lines := strings.Split(string(pdf[start:]), "\n")
+	var first, n int
+	fmt.Sscan(lines[1], &first, &n)
+	for obj := 1; obj < n; obj++ {
+		off, _ := strconv.Atoi(lines[2+obj][:10])
+		if want := fmt.Sprintf("%d 0 obj", obj); !bytes.HasPrefix(pdf[off:], []byte(want)) {
+			t.Fatalf("xref entry %d points at %q", obj, pdf[off:off+12])
+		}
+	}
+}
+
+// contents inflates every page content stream.
+func contents(t *testing.T, pdf []byte) string {
+	t.Helper()
+	var sb strings.Builder
+	re := regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`)
+	for _, m := range re.FindAllSubmatch(pdf, -1) {
+		zr, err := zlib.NewReader(bytes.NewReader(m[1]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(zr)
+		sb.Write(b)
+	}
+	return sb.String()
+}
+
+func TestBooklet(t *testing.T) {
+	var b domain.Board
+	b.Values[0][0], b.Fixed[0][0] = 5, true
+	b.Values[0][1] = 3 // a player entry, not printed
+	sol := domain.Board{}
+	sol.Values[8][8] = 7
+	entries := make([]Entry, 5)
+	for i := range entries {
+		entries[i] = Entry{Puzzle: domain.Puzzle{Board: b, Difficulty: domain.Hard}}
+	}
+	entries[1].Puzzle.Name = "Sunday (hard)"
+	entries[2].Solution = &sol
+
+	var out bytes.Buffer
+	if err := Booklet(&out, entries, Options{Title: "Club", PerPage: 4, Candidates: true}); err != nil {
+		t.Fatal(err)
+	}
+	pdf := out.Bytes()
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Fatal("missing PDF header or trailer")
+	}
+	checkXref(t, pdf)
+	if !bytes.Contains(pdf, []byte("/Count 3 ")) {
+		t.Fatal("want two puzzle pages and one answer page")
+	}
+	text := contents(t, pdf)
+	for _, want := range []string{"(Club)", "(Puzzle 1 - hard)", `(2. Sunday \(hard\) - hard)`, "(Club - Answers)", "(3)", "(7)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("content lacks %s", want)
+		}
+	}
+	if g := entries[0].Puzzle.Givens(); g.Values[0][0] != 5 || g.Values[0][1] != 0 {
+		t.Errorf("givens = %v", g.Values[0])
+	}
+}
+
+func TestBookletEmpty(t *testing.T) {
+	if err := Booklet(io.Discard, nil, Options{}); err == nil {
+		t.Fatal("expected an error for no puzzles")
+	}
+}
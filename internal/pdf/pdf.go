@@ -0,0 +1,155 @@
+// Package pdf writes small vector PDF documents: lines, rectangles and text
+// in the standard Helvetica fonts, which every viewer provides, so no font
+// is embedded and nothing outside the standard library is needed.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A4 page size in points.
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+)
+
+// DigitWidth is the advance of every digit in both Helvetica faces, as a
+// fraction of the font size. Centring digits needs no other metrics.
+const DigitWidth = 0.556
+
+// Doc is a document under construction.
+type Doc struct {
+	W, H  float64
+	Title string
+	pages []*Page
+}
+
+// New starts a document whose pages are w×h points.
+func New(w, h float64) *Doc {
+	return &Doc{W: w, H: h}
+}
+
+// Page is one page's content stream. Coordinates are points from the
+// bottom-left corner.
+type Page struct {
+	buf bytes.Buffer
+}
+
+// AddPage appends an empty page and returns it for drawing.
+func (d *Doc) AddPage() *Page {
+	p := &Page{}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Pages reports how many pages were added.
+func (d *Doc) Pages() int { return len(d.pages) }
+
+// num formats a coordinate to a hundredth of a point.
+func num(f float64) string {
+	s := strconv.FormatFloat(f, 'f', 2, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// Line strokes a line width points wide.
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.buf, "%s w %s %s m %s %s l S\n", num(width), num(x1), num(y1), num(x2), num(y2))
+}
+
+// Rect strokes the outline of a rectangle.
+func (p *Page) Rect(x, y, w, h, width float64) {
+	fmt.Fprintf(&p.buf, "%s w %s %s %s %s re S\n", num(width), num(x), num(y), num(w), num(h))
+}
+
+// Gray sets the fill and stroke colour for what follows: 0 is black, 1
+// white.
+func (p *Page) Gray(level float64) {
+	fmt.Fprintf(&p.buf, "%s g %s G\n", num(level), num(level))
+}
+
+// Text draws s with its baseline starting at (x, y). Characters outside
+// Latin-1 print as '?'.
+func (p *Page) Text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&p.buf, "BT /%s %s Tf %s %s Td (%s) Tj ET\n", font, num(size), num(x), num(y), escape(s))
+}
+
+// escape writes s as the body of a PDF string in WinAnsiEncoding, which
+// agrees with Latin-1 for the characters kept.
+func escape(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r >= 32 && r < 127:
+			sb.WriteRune(r)
+		case r >= 160 && r <= 255:
+			fmt.Fprintf(&sb, "\\%03o", r)
+		default:
+			sb.WriteByte('?')
+		}
+	}
+	return sb.String()
+}
+
+// Write serialises the document. Page contents are Flate-compressed.
+func (d *Doc) Write(w io.Writer) error {
+	var out bytes.Buffer
+	var offsets []int
+	obj := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1-4 are fixed; each page then takes a page and a content object.
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d /MediaBox [0 0 %s %s] >>",
+		strings.Join(kids, " "), len(d.pages), num(d.W), num(d.H)))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, p := range d.pages {
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", 6+2*i))
+		var z bytes.Buffer
+		zw := zlib.NewWriter(&z)
+		if _, err := zw.Write(p.buf.Bytes()); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		obj(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", z.Len(), z.Bytes()))
+	}
+	info := ""
+	if d.Title != "" {
+		obj(fmt.Sprintf("<< /Title (%s) /Producer (sudoku) >>", escape(d.Title)))
+		info = fmt.Sprintf(" /Info %d 0 R", len(offsets))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R%s >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, info, xref)
+	_, err := out.WriteTo(w)
+	return err
+}
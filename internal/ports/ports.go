@@ -24,19 +24,32 @@ type Generator interface {
 	Generate(ctx context.Context, seed int64, difficulty domain.Difficulty) (*domain.Puzzle, Stats, error)
 }
 
-// Validator performs fast constraint checks (row/col/box).
+// Validator performs fast constraint checks (row/col/box). Contradictions
+// goes deeper and reports dead ends (cells or digits with nowhere to go)
+// without running a solver.
 type Validator interface {
 	Validate(ctx context.Context, b *domain.Board) (ok bool, conflicts []domain.CellCoord, err error)
+	Contradictions(ctx context.Context, b *domain.Board) ([]domain.Contradiction, error)
 }
 
-// Hinter returns the next logical step up to a max strategy tier.
+// Hinter returns the next logical step up to a max strategy tier. HintFrom
+// runs the strategies against caller-supplied candidates (the player's pencil
+// marks) instead of recomputing them from the placed values.
 type Hinter interface {
 	Hint(ctx context.Context, b *domain.Board, max domain.StrategyTier) (domain.Hint, bool, error)
+	HintFrom(ctx context.Context, b *domain.Board, cands *domain.Marks, max domain.StrategyTier) (domain.Hint, bool, error)
 }
 
-// Storage persists and retrieves puzzles as JSON.
+// Storage persists and retrieves puzzles and game sessions as JSON. Delete
+// may keep the puzzle in a trash for a while: it returns until when Restore
+// can bring it back, or the zero time when it is gone for good.
 type Storage interface {
 	Save(ctx context.Context, p *domain.Puzzle) error
 	Load(ctx context.Context, id string) (*domain.Puzzle, error)
 	List(ctx context.Context) ([]domain.PuzzleMeta, error)
+	Update(ctx context.Context, id string, patch domain.PuzzlePatch) (*domain.Puzzle, error)
+	Delete(ctx context.Context, id string) (time.Time, error)
+	Restore(ctx context.Context, id string) (*domain.Puzzle, error)
+	SaveGame(ctx context.Context, g *domain.Game) error
+	LoadGame(ctx context.Context, id string) (*domain.Game, error)
 }
\ No newline at end of file
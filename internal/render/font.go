@@ -0,0 +1,79 @@
+package render
+
+import (
+	"image"
+	"math"
+)
+
+type pt struct{ x, y float64 }
+
+// strokes draws each digit as polylines in a 4×6 box, y pointing down. The
+// glyphs scale to any size, and stay simple enough to double as templates
+// for recognising printed digits.
+var strokes = [10][][]pt{
+	{{{1, 0}, {3, 0}, {4, 1}, {4, 5}, {3, 6}, {1, 6}, {0, 5}, {0, 1}, {1, 0}}},
+	{{{1, 1}, {2, 0}, {2, 6}}, {{1, 6}, {3, 6}}},
+	{{{0, 1}, {1, 0}, {3, 0}, {4, 1}, {4, 2}, {0, 6}, {4, 6}}},
+	{{{0, 0}, {4, 0}, {2, 2.5}, {3, 2.5}, {4, 3.5}, {4, 5}, {3, 6}, {1, 6}, {0, 5}}},
+	{{{3, 6}, {3, 0}, {0, 4}, {4, 4}}},
+	{{{4, 0}, {0, 0}, {0, 2.5}, {3, 2.5}, {4, 3.5}, {4, 5}, {3, 6}, {0, 6}}},
+	{{{3, 0}, {1, 0}, {0, 1}, {0, 5}, {1, 6}, {3, 6}, {4, 5}, {4, 3.5}, {3, 2.5}, {0, 2.5}}},
+	{{{0, 0}, {4, 0}, {1.5, 6}}},
+	{
+		{{1, 0}, {3, 0}, {4, 1}, {4, 2}, {3, 3}, {1, 3}, {0, 2}, {0, 1}, {1, 0}},
+		{{1, 3}, {0, 4}, {0, 5}, {1, 6}, {3, 6}, {4, 5}, {4, 4}, {3, 3}},
+	},
+	{{{4, 3.5}, {1, 3.5}, {0, 2.5}, {0, 1}, {1, 0}, {3, 0}, {4, 1}, {4, 5}, {3, 6}, {1, 6}}},
+}
+
+// GlyphAspect is the width of a digit relative to its height.
+const GlyphAspect = 4.0 / 6.0
+
+// Glyph rasterises digit d, anti-aliased, into a w×h coverage mask. The
+// stroke is weight times the glyph height thick, and the strokes are
+// inset so they stay inside the mask.
+func Glyph(d uint8, w, h int, weight float64) *image.Alpha {
+	m := image.NewAlpha(image.Rect(0, 0, w, h))
+	if d > 9 || w <= 0 || h <= 0 {
+		return m
+	}
+	half := weight * float64(h) / 2
+	sx := (float64(w) - 2*half) / 4
+	sy := (float64(h) - 2*half) / 6
+	for _, line := range strokes[d] {
+		for i := 1; i < len(line); i++ {
+			a := pt{half + line[i-1].x*sx, half + line[i-1].y*sy}
+			b := pt{half + line[i].x*sx, half + line[i].y*sy}
+			stroke(m, a, b, half)
+		}
+	}
+	return m
+}
+
+// stroke adds the coverage of a segment of half-width r to m, keeping the
+// maximum where segments overlap.
+func stroke(m *image.Alpha, a, b pt, r float64) {
+	x0 := int(math.Floor(math.Min(a.x, b.x) - r - 1))
+	x1 := int(math.Ceil(math.Max(a.x, b.x) + r + 1))
+	y0 := int(math.Floor(math.Min(a.y, b.y) - r - 1))
+	y1 := int(math.Ceil(math.Max(a.y, b.y) + r + 1))
+	bounds := m.Bounds()
+	for y := max(y0, bounds.Min.Y); y < min(y1, bounds.Max.Y); y++ {
+		for x := max(x0, bounds.Min.X); x < min(x1, bounds.Max.X); x++ {
+			d := segDist(pt{float64(x) + 0.5, float64(y) + 0.5}, a, b)
+			cov := math.Max(0, math.Min(1, r+0.5-d))
+			if v := uint8(cov * 255); v > m.AlphaAt(x, y).A {
+				m.Pix[m.PixOffset(x, y)] = v
+			}
+		}
+	}
+}
+
+func segDist(p, a, b pt) float64 {
+	dx, dy := b.x-a.x, b.y-a.y
+	t := 0.0
+	if l := dx*dx + dy*dy; l > 0 {
+		t = math.Max(0, math.Min(1, ((p.x-a.x)*dx+(p.y-a.y)*dy)/l))
+	}
+	return math.Hypot(p.x-a.x-t*dx, p.y-a.y-t*dy)
+}
@@ -0,0 +1,64 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+
+	"svw.info/sudoku/internal/domain"
+)
+
+// Stroke weights of the built-in font relative to the glyph height.
+const (
+	regularWeight = 0.09
+	boldWeight    = 0.13
+)
+
+// Image rasterises b.
+func Image(b *domain.Board, opt Options) (*image.RGBA, error) {
+	s, err := layout(b, opt)
+	if err != nil {
+		return nil, err
+	}
+	img := image.NewRGBA(image.Rect(0, 0, s.size, s.size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(s.theme.Background), image.Point{}, draw.Src)
+	for _, r := range s.rects {
+		fill(img, r.x, r.y, r.w, r.h, r.c)
+	}
+	for _, g := range s.glyphs {
+		weight := regularWeight
+		if g.bold {
+			weight = boldWeight
+		}
+		x, y := int(math.Round(g.x)), int(math.Round(g.y))
+		w, h := int(math.Round(g.w)), int(math.Round(g.h))
+		mask := Glyph(g.d, w, h, weight)
+		draw.DrawMask(img, image.Rect(x, y, x+w, y+h), image.NewUniform(g.c), image.Point{}, mask, image.Point{}, draw.Over)
+	}
+	return img, nil
+}
+
+// PNG writes b as a PNG image.
+func PNG(w io.Writer, b *domain.Board, opt Options) error {
+	img, err := Image(b, opt)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// fill paints a rectangle given in fractional pixels, rounding its edges to
+// the pixel grid.
+func fill(img *image.RGBA, x, y, w, h float64, c color.RGBA) {
+	r := image.Rect(int(math.Round(x)), int(math.Round(y)), int(math.Round(x+w)), int(math.Round(y+h)))
+	if r.Dx() == 0 {
+		r.Max.X++
+	}
+	if r.Dy() == 0 {
+		r.Max.Y++
+	}
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
@@ -0,0 +1,228 @@
+// Package render draws boards as SVG or PNG images for embedding in pages,
+// newsletters and chat messages. PNGs are rasterised with the standard
+// image packages and the built-in stroke font, so no fonts or cgo are
+// needed.
+package render
+
+import (
+	"image/color"
+
+	"svw.info/sudoku/internal/domain"
+)
+
+// Image sizes in pixels.
+const (
+	DefaultSize = 450
+	MinSize     = 90
+	MaxSize     = 2000
+)
+
+// Theme is the palette of an image.
+type Theme struct {
+	Background color.RGBA
+	Line       color.RGBA // cell borders
+	Box        color.RGBA // box borders and frame
+	Given      color.RGBA
+	Entry      color.RGBA // player entries
+	Solution   color.RGBA // solution digits in empty cells
+	Mark       color.RGBA // pencil marks
+	Highlight  color.RGBA // cells of a hint
+	House      color.RGBA // house of a hint
+	Elim       color.RGBA // candidates a hint eliminates
+}
+
+func rgb(hex uint32) color.RGBA {
+	return color.RGBA{uint8(hex >> 16), uint8(hex >> 8), uint8(hex), 0xff}
+}
+
+// Themes are the palettes selectable by name.
+var Themes = map[string]Theme{
+	"light": {
+		Background: rgb(0xffffff), Line: rgb(0xb0b7c3), Box: rgb(0x1f2937),
+		Given: rgb(0x111827), Entry: rgb(0x2563eb), Solution: rgb(0x059669), Mark: rgb(0x6b7280),
+		Highlight: rgb(0xfde68a), House: rgb(0xeef2ff), Elim: rgb(0xdc2626),
+	},
+	"dark": {
+		Background: rgb(0x111827), Line: rgb(0x374151), Box: rgb(0xd1d5db),
+		Given: rgb(0xf9fafb), Entry: rgb(0x60a5fa), Solution: rgb(0x34d399), Mark: rgb(0x9ca3af),
+		Highlight: rgb(0x854d0e), House: rgb(0x1e293b), Elim: rgb(0xf87171),
+	},
+	"print": {
+		Background: rgb(0xffffff), Line: rgb(0x999999), Box: rgb(0x000000),
+		Given: rgb(0x000000), Entry: rgb(0x444444), Solution: rgb(0x777777), Mark: rgb(0x777777),
+		Highlight: rgb(0xdddddd), House: rgb(0xf2f2f2), Elim: rgb(0x000000),
+	},
+}
+
+// Options selects what is drawn. The zero value draws the board alone at
+// DefaultSize in the light theme.
+type Options struct {
+	Size     int           // edge length in pixels, clamped to MinSize..MaxSize
+	Theme    string        // key of Themes; "" is light
+	Solution *domain.Board // fills the empty cells
+	Hint     *domain.Hint  // highlights its cells and house, shows eliminations
+	Marks    *domain.Marks // pencil marks of the empty cells
+}
+
+// Given cells are b's Fixed cells; when none are marked, every value counts
+// as a given.
+func isGiven(b *domain.Board, r, c int, anyFixed bool) bool {
+	if anyFixed {
+		return b.Fixed[r][c]
+	}
+	return b.Values[r][c] != 0
+}
+
+type rect struct {
+	x, y, w, h float64
+	c          color.RGBA
+}
+
+// glyph is a digit centred in its box.
+type glyph struct {
+	x, y, w, h float64
+	d          uint8
+	c          color.RGBA
+	bold       bool
+}
+
+// scene is an image as filled rectangles under digits, shared by both
+// output formats.
+type scene struct {
+	size   int
+	theme  Theme
+	rects  []rect
+	glyphs []glyph
+}
+
+func layout(b *domain.Board, opt Options) (scene, error) {
+	name := opt.Theme
+	if name == "" {
+		name = "light"
+	}
+	th, ok := Themes[name]
+	if !ok {
+		return scene{}, domain.Errorf(domain.KindInvalid, "unknown theme %q", opt.Theme)
+	}
+	size := opt.Size
+	if size == 0 {
+		size = DefaultSize
+	}
+	size = max(MinSize, min(size, MaxSize))
+	s := scene{size: size, theme: th}
+
+	pad := float64(size) / 40
+	thick := max(2, float64(size)/150)
+	thin := max(1, float64(size)/450)
+	cell := (float64(size) - 2*pad) / 9
+	at := func(r, c int) (float64, float64) { return pad + float64(c)*cell, pad + float64(r)*cell }
+
+	anyFixed := false
+	for r := 0; r < 9; r++ {
+		for c := 0; c < 9; c++ {
+			anyFixed = anyFixed || b.Fixed[r][c]
+		}
+	}
+
+	// Hint backgrounds: the house first, then the cells and fins on top.
+	// Hints may come from clients, so whatever lies off the grid is skipped.
+	elims := map[int]domain.Digits{}
+	if h := opt.Hint; h != nil {
+		if h.House != nil && inRange(h.House.Index) {
+			hidx := -1
+			switch h.House.Kind {
+			case domain.HouseRow:
+				hidx = h.House.Index
+			case domain.HouseCol:
+				hidx = h.House.Index + 9
+			case domain.HouseBox:
+				hidx = h.House.Index + 18
+			}
+			if hidx >= 0 {
+				for _, cellIdx := range domain.Houses[hidx] {
+					x, y := at(cellIdx/9, cellIdx%9)
+					s.rects = append(s.rects, rect{x, y, cell, cell, th.House})
+				}
+			}
+		}
+		cells := append([]domain.CellCoord(nil), h.Cells...)
+		if h.Fish != nil {
+			cells = append(cells, h.Fish.Fins...)
+		}
+		for _, cc := range cells {
+			if inRange(cc.Row) && inRange(cc.Col) {
+				x, y := at(cc.Row, cc.Col)
+				s.rects = append(s.rects, rect{x, y, cell, cell, th.Highlight})
+			}
+		}
+		for _, e := range h.Eliminations {
+			if inRange(e.Row) && inRange(e.Col) && e.Digit >= 1 && e.Digit <= 9 {
+				i := domain.CellIndex(e.Row, e.Col)
+				elims[i] = elims[i].With(e.Digit)
+			}
+		}
+	}
+
+	// Thin lines under thick ones so box corners stay solid.
+	for i := 0; i <= 9; i++ {
+		if i%3 == 0 {
+			continue
+		}
+		off := pad + float64(i)*cell
+		s.rects = append(s.rects,
+			rect{off - thin/2, pad, thin, 9 * cell, th.Line},
+			rect{pad, off - thin/2, 9 * cell, thin, th.Line})
+	}
+	for i := 0; i <= 9; i += 3 {
+		off := pad + float64(i)*cell
+		s.rects = append(s.rects,
+			rect{off - thick/2, pad - thick/2, thick, 9*cell + thick, th.Box},
+			rect{pad - thick/2, off - thick/2, 9*cell + thick, thick, th.Box})
+	}
+
+	big, small := cell*0.62, cell/3*0.68
+	for r := 0; r < 9; r++ {
+		for c := 0; c < 9; c++ {
+			x, y := at(r, c)
+			v := b.Values[r][c]
+			col, bold := th.Entry, false
+			switch {
+			case v != 0 && isGiven(b, r, c, anyFixed):
+				col, bold = th.Given, true
+			case v == 0 && opt.Solution != nil && opt.Solution.Values[r][c] != 0:
+				v, col = opt.Solution.Values[r][c], th.Solution
+			}
+			if v > 9 {
+				v = 0
+			}
+			if v != 0 {
+				s.glyphs = append(s.glyphs, centred(x, y, cell, big, v, col, bold))
+				continue
+			}
+			var marks domain.Digits
+			if opt.Marks != nil {
+				marks = opt.Marks[r][c] & domain.AllDigits
+			}
+			el := elims[domain.CellIndex(r, c)]
+			sub := cell / 3
+			for _, d := range (marks | el).List() {
+				col := th.Mark
+				if el.Has(d) {
+					col = th.Elim
+				}
+				sx, sy := x+float64((d-1)%3)*sub, y+float64((d-1)/3)*sub
+				s.glyphs = append(s.glyphs, centred(sx, sy, sub, small, d, col, el.Has(d)))
+			}
+		}
+	}
+	return s, nil
+}
+
+func inRange(i int) bool { return i >= 0 && i < 9 }
+
+// centred places a digit of height h in the middle of a box of edge box at
+// (x, y).
+func centred(x, y, box, h float64, d uint8, c color.RGBA, bold bool) glyph {
+	w := h * GlyphAspect
+	return glyph{x + (box-w)/2, y + (box-h)/2, w, h, d, c, bold}
+}
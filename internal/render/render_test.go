@@ -0,0 +1,87 @@
+package render
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"svw.info/sudoku/internal/domain"
+)
+
+func TestSVG(t *testing.T) {
+	var b domain.Board
+	b.Values[0][0], b.Fixed[0][0] = 5, true
+	b.Values[4][4] = 7
+	var marks domain.Marks
+	marks[8][8] = domain.Digits(0).With(2).With(9)
+	hint := domain.Hint{
+		Cells:        []domain.CellCoord{{Row: 0, Col: 0}},
+		Eliminations: []domain.Candidate{{Row: 8, Col: 8, Digit: 9}},
+	}
+
+	var out bytes.Buffer
+	if err := SVG(&out, &b, Options{Size: 300, Theme: "dark", Marks: &marks, Hint: &hint}); err != nil {
+		t.Fatal(err)
+	}
+	svg := out.String()
+	th := Themes["dark"]
+	for _, want := range []string{`width="300"`, `font-weight="bold"`, ">5</text>", ">7</text>", ">2</text>", ">9</text>", hex(th.Highlight), hex(th.Elim)} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SVG lacks %s", want)
+		}
+	}
+	if err := SVG(&out, &b, Options{Theme: "neon"}); err == nil {
+		t.Error("expected an error for an unknown theme")
+	}
+}
+
+func TestPNG(t *testing.T) {
+	var b, sol domain.Board
+	b.Values[0][0] = 1
+	sol.Values[0][1] = 2
+	var out bytes.Buffer
+	if err := PNG(&out, &b, Options{Size: 5000, Solution: &sol}); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := img.Bounds().Dx(); got != MaxSize {
+		t.Fatalf("width = %d, want %d", got, MaxSize)
+	}
+}
+
+func TestGlyph(t *testing.T) {
+	for d := uint8(0); d <= 9; d++ {
+		m := Glyph(d, 20, 30, 0.1)
+		ink := 0
+		for _, a := range m.Pix {
+			ink += int(a)
+		}
+		if ink == 0 {
+			t.Errorf("digit %d is blank", d)
+		}
+	}
+}
+
+func TestBadHintIsSkipped(t *testing.T) {
+	var b domain.Board
+	b.Values[0][0] = 12
+	var marks domain.Marks
+	marks[1][1] = 0xFFFF
+	hints := []domain.Hint{
+		{House: &domain.House{Kind: domain.HouseRow, Index: 40}},
+		{House: &domain.House{Kind: domain.HouseBox, Index: -1}},
+		{House: &domain.House{Kind: "diagonal", Index: 0}},
+		{Cells: []domain.CellCoord{{Row: 9, Col: 0}}, Fish: &domain.Fish{Fins: []domain.CellCoord{{Row: 0, Col: -3}}}},
+		{Eliminations: []domain.Candidate{{Row: 0, Col: 0, Digit: 15}, {Row: -1, Col: 4, Digit: 3}}},
+	}
+	for _, h := range hints {
+		var out bytes.Buffer
+		if err := PNG(&out, &b, Options{Size: MinSize, Marks: &marks, Hint: &h}); err != nil {
+			t.Errorf("hint %+v: %v", h, err)
+		}
+	}
+}
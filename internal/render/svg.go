@@ -0,0 +1,46 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"strconv"
+
+	"svw.info/sudoku/internal/domain"
+)
+
+// SVG writes b as an SVG document. Digits are text in a sans-serif font, so
+// they stay sharp at any zoom.
+func SVG(w io.Writer, b *domain.Board, opt Options) error {
+	s, err := layout(b, opt)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", s.size, s.size, s.size, s.size)
+	fmt.Fprintf(bw, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", hex(s.theme.Background))
+	for _, r := range s.rects {
+		fmt.Fprintf(bw, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s"/>`+"\n", f2(r.x), f2(r.y), f2(r.w), f2(r.h), hex(r.c))
+	}
+	fmt.Fprintln(bw, `<g font-family="Helvetica, Arial, sans-serif" text-anchor="middle" dominant-baseline="central">`)
+	for _, g := range s.glyphs {
+		weight := ""
+		if g.bold {
+			weight = ` font-weight="bold"`
+		}
+		// Font size is the em box; digits are about 0.72 em tall.
+		fmt.Fprintf(bw, `<text x="%s" y="%s" font-size="%s" fill="%s"%s>%d</text>`+"\n",
+			f2(g.x+g.w/2), f2(g.y+g.h/2), f2(g.h/0.72), hex(g.c), weight, g.d)
+	}
+	fmt.Fprintln(bw, "</g>\n</svg>")
+	return bw.Flush()
+}
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func f2(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
@@ -0,0 +1,110 @@
+package replay
+
+import (
+	"sort"
+
+	"svw.info/sudoku/internal/domain"
+)
+
+// maxPauses caps how many of the longest pauses are reported.
+const maxPauses = 5
+
+// Step is a logged move with its timing relative to the start of the game.
+type Step struct {
+	domain.Move
+	Index    int   `json:"index"`
+	OffsetMs int64 `json:"offsetMs"` // since game creation
+	DeltaMs  int64 `json:"deltaMs"`  // since the previous move
+	Undone   bool  `json:"undone"`   // taken back by an undo and not redone
+}
+
+// Pause is a gap before a move.
+type Pause struct {
+	Index      int   `json:"index"` // move that ended the pause
+	DurationMs int64 `json:"durationMs"`
+}
+
+// RegionTime is the thinking time spent before moves in one 3×3 box.
+type RegionTime struct {
+	Box        int   `json:"box"` // 0..8, row-major
+	DurationMs int64 `json:"durationMs"`
+	Moves      int   `json:"moves"`
+}
+
+// Analytics summarizes where a player spent time and what they took back.
+type Analytics struct {
+	DurationMs    int64        `json:"durationMs"`
+	Regions       []RegionTime `json:"regions"`
+	LongestPauses []Pause      `json:"longestPauses"`
+	Undone        []int        `json:"undone"` // indices of moves taken back and not redone
+}
+
+// Replay is the ordered move log of a game plus its analytics.
+type Replay struct {
+	GameID    string       `json:"gameId"`
+	Start     domain.Board `json:"start"`
+	Steps     []Step       `json:"steps"`
+	Analytics Analytics    `json:"analytics"`
+}
+
+// Build derives the replay of g. The time before each cell move is charged to
+// that move's box, on the view that it was spent working out the move. Which
+// moves were taken back comes from g.History, as for the game's own board.
+func Build(g *domain.Game) Replay {
+	steps := make([]Step, len(g.Moves))
+	regions := make([]RegionTime, 9)
+	for i := range regions {
+		regions[i].Box = i
+	}
+	applied, _ := g.History()
+	inEffect := make(map[int]bool, len(applied))
+	for _, i := range applied {
+		inEffect[i] = true
+	}
+	prev := g.CreatedAt
+	for i, m := range g.Moves {
+		delta := nanosToMs(m.At - prev)
+		if delta < 0 {
+			delta = 0
+		}
+		prev = m.At
+		steps[i] = Step{Move: m, Index: i, OffsetMs: nanosToMs(m.At - g.CreatedAt), DeltaMs: delta}
+		if m.Kind != domain.MoveUndo && m.Kind != domain.MoveRedo {
+			steps[i].Undone = !inEffect[i]
+			box := (m.Row/3)*3 + m.Col/3
+			regions[box].DurationMs += delta
+			regions[box].Moves++
+		}
+	}
+
+	pauses := make([]Pause, 0, len(steps))
+	undone := []int{}
+	for _, s := range steps {
+		pauses = append(pauses, Pause{Index: s.Index, DurationMs: s.DeltaMs})
+		if s.Undone {
+			undone = append(undone, s.Index)
+		}
+	}
+	sort.SliceStable(pauses, func(i, j int) bool { return pauses[i].DurationMs > pauses[j].DurationMs })
+	if len(pauses) > maxPauses {
+		pauses = pauses[:maxPauses]
+	}
+
+	var total int64
+	if n := len(g.Moves); n > 0 {
+		total = nanosToMs(g.Moves[n-1].At - g.CreatedAt)
+	}
+	return Replay{
+		GameID: g.ID,
+		Start:  g.Start,
+		Steps:  steps,
+		Analytics: Analytics{
+			DurationMs:    total,
+			Regions:       regions,
+			LongestPauses: pauses,
+			Undone:        undone,
+		},
+	}
+}
+
+func nanosToMs(n int64) int64 { return n / 1e6 }
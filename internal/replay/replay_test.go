@@ -0,0 +1,62 @@
+package replay
+
+import (
+	"testing"
+
+	"svw.info/sudoku/internal/domain"
+)
+
+func TestBuildAnalytics(t *testing.T) {
+	const ms = int64(1e6)
+	g := domain.NewGame("g1", &domain.Puzzle{ID: "p1"}, 0)
+	moves := []domain.Move{
+		{Kind: domain.MoveSet, Row: 0, Col: 0, Value: 1, At: 1000 * ms},   // box 0, 1s
+		{Kind: domain.MoveSet, Row: 4, Col: 4, Value: 2, At: 9000 * ms},   // box 4, 8s
+		{Kind: domain.MoveUndo, At: 9500 * ms},                            // undoes move 1
+		{Kind: domain.MoveNote, Row: 4, Col: 5, Value: 3, At: 12000 * ms}, // box 4, 2.5s
+	}
+	for _, m := range moves {
+		if err := g.Play(m); err != nil {
+			t.Fatalf("Play: %v", err)
+		}
+	}
+
+	rp := Build(g)
+	if len(rp.Steps) != 4 || rp.Steps[3].OffsetMs != 12000 || rp.Steps[3].DeltaMs != 2500 {
+		t.Fatalf("unexpected steps: %+v", rp.Steps)
+	}
+	a := rp.Analytics
+	if a.DurationMs != 12000 {
+		t.Fatalf("DurationMs = %d", a.DurationMs)
+	}
+	if a.Regions[0].DurationMs != 1000 || a.Regions[4].DurationMs != 10500 || a.Regions[4].Moves != 2 {
+		t.Fatalf("regions = %+v", a.Regions)
+	}
+	if len(a.Undone) != 1 || a.Undone[0] != 1 || !rp.Steps[1].Undone {
+		t.Fatalf("undone = %v", a.Undone)
+	}
+	if a.LongestPauses[0].Index != 1 || a.LongestPauses[0].DurationMs != 8000 {
+		t.Fatalf("longest pause = %+v", a.LongestPauses[0])
+	}
+}
+
+func TestBuildFollowsHistory(t *testing.T) {
+	g := domain.NewGame("g1", &domain.Puzzle{ID: "p1"}, 0)
+	for _, m := range []domain.Move{
+		{Kind: domain.MoveSet, Row: 0, Col: 0, Value: 1, At: 1},
+		{Kind: domain.MoveSet, Row: 0, Col: 1, Value: 2, At: 2},
+		{Kind: domain.MoveUndo, At: 3}, // takes back move 1...
+		{Kind: domain.MoveRedo, At: 4}, // ...and puts it back
+		{Kind: domain.MoveUndo, At: 5}, // takes back move 1 again
+		{Kind: domain.MoveUndo, At: 6}, // and move 0
+		{Kind: domain.MoveRedo, At: 7}, // move 0 is back; move 1 stays undone
+	} {
+		if err := g.Play(m); err != nil {
+			t.Fatalf("Play: %v", err)
+		}
+	}
+	rp := Build(g)
+	if u := rp.Analytics.Undone; len(u) != 1 || u[0] != 1 || rp.Steps[0].Undone || !rp.Steps[1].Undone {
+		t.Fatalf("undone = %v", u)
+	}
+}
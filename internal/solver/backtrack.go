@@ -1,38 +1,35 @@
 package solver
 
-// BacktrackingSolver is a straightforward recursive solver.
+import "svw.info/sudoku/internal/domain"
+
+// BacktrackingSolver is a straightforward recursive solver. It branches on
+// the empty cell with the fewest candidates (MRV) of a domain.Grid.
 type BacktrackingSolver struct{}
 
 func NewBacktrackingSolver() *BacktrackingSolver { return &BacktrackingSolver{} }
 
 // --- helpers used by Solve/Unique (in other files) ---
-func isValid(b *[9][9]uint8, r, c int, v uint8) bool {
-	for i := 0; i < 9; i++ {
-		if b[r][i] == v || b[i][c] == v {
-			return false
-		}
+
+// search tries every candidate of the most constrained cell and calls done on
+// each complete grid; done returns true to stop. It reports whether the
+// search was stopped.
+func search(g *domain.Grid, nodes *int, stop func() bool, done func(*domain.Grid) bool) bool {
+	if stop() {
+		return true
 	}
-	br, bc := (r/3)*3, (c/3)*3
-	for dr := 0; dr < 3; dr++ {
-		for dc := 0; dc < 3; dc++ {
-			if b[br+dr][bc+dc] == v {
-				return false
-			}
-		}
+	cell := g.BestCell()
+	if cell < 0 {
+		return done(g)
 	}
-	return true
-}
-
-func findEmpty(b *[9][9]uint8) (int, int, bool) {
-	for r := 0; r < 9; r++ {
-		for c := 0; c < 9; c++ {
-			if b[r][c] == 0 {
-				return r, c, true
-			}
+	for _, v := range g.Cands[cell].List() {
+		*nodes++
+		next := *g
+		if next.Place(cell, v) && search(&next, nodes, stop, done) {
+			return true
 		}
 	}
-	return 0, 0, false
+	return false
 }
 
 // The implementations for Solve and Unique are in backtrack_solve.go and backtrack_unique.go,
-// and use the helpers above.
\ No newline at end of file
+// and use the helper above.
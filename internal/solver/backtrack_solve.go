@@ -2,7 +2,6 @@ package solver
 
 import (
 	"context"
-	"errors"
 	"time"
 
 	"svw.info/sudoku/internal/domain"
@@ -11,32 +10,24 @@ import (
 
 func (s *BacktrackingSolver) Solve(ctx context.Context, b *domain.Board) (*domain.Board, ports.Stats, error) {
 	start := time.Now()
-	grid := b.Values
+	g, ok := domain.NewGrid(b)
+	if !ok {
+		return nil, ports.Stats{Duration: time.Since(start)}, errConflict
+	}
 	nodes := 0
-	var dfs func() bool
-	dfs = func() bool {
-		if ctx.Err() != nil {
-			return false
-		}
-		r, c, ok := findEmpty(&grid)
-		if !ok {
-			return true
+	var solved *domain.Grid
+	search(&g, &nodes, func() bool { return ctx.Err() != nil }, func(done *domain.Grid) bool {
+		solved = done
+		return true
+	})
+	if solved == nil {
+		err := ctx.Err()
+		if err == nil {
+			err = errNoSolution
 		}
-		for v := uint8(1); v <= 9; v++ {
-			nodes++
-			if isValid(&grid, r, c, v) {
-				grid[r][c] = v
-				if dfs() {
-					return true
-				}
-				grid[r][c] = 0
-			}
-		}
-		return false
-	}
-	if !dfs() {
-		return nil, ports.Stats{Nodes: nodes, Duration: time.Since(start)}, errors.New("unsolvable or canceled")
+		return nil, ports.Stats{Nodes: nodes, Duration: time.Since(start)}, err
 	}
-	out := &domain.Board{Values: grid, Fixed: b.Fixed}
-	return out, ports.Stats{Nodes: nodes, Duration: time.Since(start)}, nil
-}
\ No newline at end of file
+	out := solved.Board()
+	out.Fixed = b.Fixed
+	return &out, ports.Stats{Nodes: nodes, Duration: time.Since(start)}, nil
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/ports"
 	"svw.info/sudoku/internal/validator"
 )
 
@@ -49,4 +50,38 @@ func TestBacktrackingSolveUnder1s(t *testing.T) {
 		t.Fatalf("took too long: %v (>1s)", st.Duration)
 	}
 	t.Logf("Solved in %v, nodes=%d", st.Duration, st.Nodes)
-}
\ No newline at end of file
+}
+func TestSolversAgree(t *testing.T) {
+	ctx := context.Background()
+	bt, dlx := NewBacktrackingSolver(), NewDLXSolver()
+	a, _, errA := bt.Solve(ctx, &domain.Board{Values: sample})
+	b, _, errB := dlx.Solve(ctx, &domain.Board{Values: sample})
+	if errA != nil || errB != nil || a.Values != b.Values {
+		t.Fatalf("solvers disagree: %v %v", errA, errB)
+	}
+
+	bad := sample
+	bad[0][2] = 5 // duplicates the 5 in r1c1
+	for name, s := range map[string]ports.Solver{"backtrack": bt, "dlx": dlx} {
+		if _, _, err := s.Solve(ctx, &domain.Board{Values: bad}); err == nil {
+			t.Fatalf("%s: solved conflicting givens", name)
+		}
+		if ok, _, _ := s.Unique(ctx, &domain.Board{Values: bad}); ok {
+			t.Fatalf("%s: conflicting givens reported unique", name)
+		}
+	}
+}
+
+func BenchmarkSolve_Backtracking(b *testing.B) {
+	s := NewBacktrackingSolver()
+	for i := 0; i < b.N; i++ {
+		_, _, _ = s.Solve(context.Background(), &domain.Board{Values: sample})
+	}
+}
+
+func BenchmarkSolve_DLX(b *testing.B) {
+	s := NewDLXSolver()
+	for i := 0; i < b.N; i++ {
+		_, _, _ = s.Solve(context.Background(), &domain.Board{Values: sample})
+	}
+}
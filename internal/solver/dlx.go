@@ -219,15 +219,24 @@ func (d *dlx) applyGiven(r, c, v int) error {
 	return nil
 }
 
+// errConflict is returned when the givens already repeat a digit in a house;
+// covering the same constraint column twice would corrupt the link structure.
+var errConflict = domain.Errorf(domain.KindInvalid, "invalid givens: out of range or conflicting values")
+
+var errNoSolution = domain.Errorf(domain.KindUnsolvable, "no solution")
+
 func (s *DLXSolver) Solve(ctx context.Context, b *domain.Board) (*domain.Board, ports.Stats, error) {
 	start := time.Now()
+	if _, ok := domain.NewGrid(b); !ok {
+		return nil, ports.Stats{}, errConflict
+	}
 	d := newDLX()
 	// apply givens
 	for r := 0; r < nSize; r++ {
 		for c := 0; c < nSize; c++ {
 			if v := int(b.Values[r][c]); v > 0 {
 				if v < 1 || v > 9 {
-					return nil, ports.Stats{}, errors.New("invalid given")
+					return nil, ports.Stats{}, domain.Errorf(domain.KindInvalid, "invalid given")
 				}
 				if err := d.applyGiven(r, c, v); err != nil {
 					return nil, ports.Stats{}, err
@@ -238,10 +247,14 @@ func (s *DLXSolver) Solve(ctx context.Context, b *domain.Board) (*domain.Board,
 	found := 0
 	_ = d.search(ctx, 0, 1, &found)
 	if found < 1 {
-		return nil, ports.Stats{Nodes: d.nodes, Duration: time.Since(start)}, errors.New("no solution")
+		err := ctx.Err()
+		if err == nil {
+			err = errNoSolution
+		}
+		return nil, ports.Stats{Nodes: d.nodes, Duration: time.Since(start)}, err
 	}
-	// reconstruct board from chosen rows in d.sol
-	var out domain.Board
+	// reconstruct board: givens plus the rows chosen in d.sol
+	out := domain.Board{Values: b.Values, Fixed: b.Fixed}
 	for i := 0; i < d.solLen; i++ {
 		rx := d.sol[i].rowIdx
 		r, c, v := decodeRow(rx)
@@ -260,12 +273,15 @@ func decodeRow(row int) (r, c, v int) {
 
 func (s *DLXSolver) Unique(ctx context.Context, b *domain.Board) (bool, ports.Stats, error) {
 	start := time.Now()
+	if _, ok := domain.NewGrid(b); !ok {
+		return false, ports.Stats{}, nil
+	}
 	d := newDLX()
 	for r := 0; r < nSize; r++ {
 		for c := 0; c < nSize; c++ {
 			if v := int(b.Values[r][c]); v > 0 {
 				if v < 1 || v > 9 {
-					return false, ports.Stats{}, errors.New("invalid given")
+					return false, ports.Stats{}, domain.Errorf(domain.KindInvalid, "invalid given")
 				}
 				if err := d.applyGiven(r, c, v); err != nil {
 					return false, ports.Stats{}, err
@@ -276,5 +292,5 @@ func (s *DLXSolver) Unique(ctx context.Context, b *domain.Board) (bool, ports.St
 	found := 0
 	_ = d.search(ctx, 0, 2, &found) // stop after finding 2 solutions
 	unique := found == 1
-	return unique, ports.Stats{Nodes: d.nodes, Duration: time.Since(start)}, nil
+	return unique, ports.Stats{Nodes: d.nodes, Duration: time.Since(start)}, ctx.Err()
 }
\ No newline at end of file
@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/generator"
+)
+
+// BatchItem is one puzzle of a batch. Err is set when the input could not
+// be read; the item is then reported without being processed.
+type BatchItem struct {
+	Board domain.Board
+	Err   error
+}
+
+// BatchResult is the outcome for one puzzle of a batch. Index is its
+// position in the input, since results arrive in completion order.
+type BatchResult struct {
+	Index      int                `json:"index"`
+	Valid      bool               `json:"valid"`
+	Conflicts  []domain.CellCoord `json:"conflicts,omitempty"`
+	Unique     bool               `json:"unique"`
+	Solution   *domain.Board      `json:"solution,omitempty"`
+	Rating     *generator.Rating  `json:"rating,omitempty"`
+	DurationMs int64              `json:"durationMs"`
+	Error      string             `json:"error,omitempty"`
+}
+
+// BatchWorkers is the default pool size: one core is left for serving.
+func BatchWorkers() int {
+	return max(1, runtime.NumCPU()-1)
+}
+
+// Batch validates, solves, checks uniqueness of and rates every item with a
+// pool of workers, calling emit from a single goroutine as results complete.
+// Rating stops at maxTier. It returns emit's first error or ctx's error;
+// either stops the remaining work.
+func (u *Service) Batch(ctx context.Context, items <-chan BatchItem, workers int, maxTier domain.StrategyTier, emit func(BatchResult) error) error {
+	if u.Solver == nil || u.Validator == nil {
+		return errNotConfigured
+	}
+	if workers < 1 {
+		workers = BatchWorkers()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type job struct {
+		index int
+		item  BatchItem
+	}
+	jobs := make(chan job)
+	results := make(chan BatchResult)
+	go func() {
+		defer close(jobs)
+		i := 0
+		for it := range items {
+			select {
+			case jobs <- job{i, it}:
+				i++
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				res := u.batchOne(ctx, j.item, maxTier)
+				res.Index = j.index
+				select {
+				case results <- res:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var err error
+	for res := range results {
+		if err == nil {
+			if err = emit(res); err != nil {
+				cancel()
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+func (u *Service) batchOne(ctx context.Context, it BatchItem, maxTier domain.StrategyTier) (res BatchResult) {
+	start := time.Now()
+	defer func() { res.DurationMs = time.Since(start).Milliseconds() }()
+	if it.Err != nil {
+		res.Error = it.Err.Error()
+		return res
+	}
+	b := &it.Board
+	ok, conflicts, err := u.Validator.Validate(ctx, b)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Valid, res.Conflicts = ok, conflicts
+	if !ok {
+		return res
+	}
+	if res.Unique, _, err = u.Solver.Unique(ctx, b); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	if res.Solution, _, err = u.Solver.Solve(ctx, b); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	if u.Hinter != nil {
+		rt, err := generator.Rate(ctx, u.Hinter, b, maxTier)
+		if err != nil {
+			res.Error = err.Error()
+			return res
+		}
+		res.Rating = &rt
+	}
+	return res
+}
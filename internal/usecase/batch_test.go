@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/hint"
+	"svw.info/sudoku/internal/solver"
+	"svw.info/sudoku/internal/validator"
+)
+
+func TestBatch(t *testing.T) {
+	uc := NewService(solver.NewBacktrackingSolver(), nil, validator.New(), hint.NewPipeline(), nil)
+	conflict := domain.Board{Values: givens}
+	conflict.Values[0][2] = 5 // second 5 in row 1
+	items := make(chan BatchItem, 3)
+	items <- BatchItem{Board: domain.Board{Values: givens}}
+	items <- BatchItem{Board: conflict}
+	items <- BatchItem{Err: errors.New("line 3: bad")}
+	close(items)
+
+	got := map[int]BatchResult{}
+	err := uc.Batch(context.Background(), items, 2, domain.StrategyUniqueness, func(res BatchResult) error {
+		got[res.Index] = res
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d results", len(got))
+	}
+	if r := got[0]; !r.Valid || !r.Unique || r.Solution == nil || r.Rating == nil || !r.Rating.Solved {
+		t.Fatalf("result 0 = %+v", r)
+	}
+	if r := got[1]; r.Valid || len(r.Conflicts) == 0 || r.Solution != nil {
+		t.Fatalf("result 1 = %+v", r)
+	}
+	if r := got[2]; r.Error != "line 3: bad" {
+		t.Fatalf("result 2 = %+v", r)
+	}
+}
@@ -0,0 +1,255 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"svw.info/sudoku/internal/codec"
+	"svw.info/sudoku/internal/domain"
+)
+
+// checkpointEvery is how many puzzles a collection import processes between
+// calls to its Checkpoint.
+const checkpointEvery = 100
+
+// CollectionOptions configures ImportCollection.
+type CollectionOptions struct {
+	Collection string   // stored on every imported puzzle
+	Tags       []string // likewise
+	MaxTier    domain.StrategyTier
+	Workers    int // solver pool size; 0 means BatchWorkers()
+	// Resume continues from an earlier report: puzzles ending on or before
+	// Resume.Line are skipped and its counts carried on.
+	Resume *ImportReport
+	// Checkpoint, if set, receives the report every checkpointEvery puzzles
+	// and once at the end. An error from it stops the import.
+	Checkpoint func(*ImportReport) error
+}
+
+// ImportIssue explains why a puzzle of a collection was not imported.
+type ImportIssue struct {
+	Line   int    `json:"line"`
+	ID     string `json:"id,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// ImportReport summarises a collection import. Line is the last input line
+// whose puzzle has been fully dealt with, which is where a resumed import
+// picks up.
+type ImportReport struct {
+	Collection string        `json:"collection"`
+	Format     codec.Format  `json:"format,omitempty"`
+	Line       int           `json:"line"`
+	Imported   int           `json:"imported"`
+	Rejected   []ImportIssue `json:"rejected,omitempty"`
+	Duplicates []ImportIssue `json:"duplicates,omitempty"`
+	Done       bool          `json:"done"`
+}
+
+// PuzzleID derives a stable ID from the values of b, so the same puzzle
+// always maps to the same stored file.
+func PuzzleID(b *domain.Board) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(codec.Line(b)))
+	return fmt.Sprintf("pz-%016x", h.Sum64())
+}
+
+var errSkipped = errors.New("skipped")
+
+// importEntry is a decoded puzzle waiting for its batch result. reject and
+// dupOf are decided before solving, so the batch skips such entries.
+type importEntry struct {
+	line   int
+	pos    int // 1 for the first puzzle of the input, rejected ones included
+	format codec.Format
+	p      domain.Puzzle
+	reject string
+	dupOf  string
+}
+
+// ImportCollection reads a collection in any codec format and saves every
+// valid puzzle with a unique solution under its PuzzleID, rated up to
+// opt.MaxTier, with the collection name and tags. Puzzles already stored
+// or repeated within the input are reported as duplicates without being
+// solved; unreadable, conflicting, unsolvable and ambiguous puzzles are
+// rejected. Puzzles are solved in parallel but recorded in input order, so
+// the report is always a consistent checkpoint. The report is returned
+// even when the import stops early.
+func (u *Service) ImportCollection(ctx context.Context, r io.Reader, opt CollectionOptions) (*ImportReport, error) {
+	if u.Storage == nil || u.Solver == nil || u.Validator == nil {
+		return nil, errNotConfigured
+	}
+	rep := &ImportReport{Collection: opt.Collection}
+	if opt.Resume != nil {
+		*rep = *opt.Resume
+		rep.Collection, rep.Done = opt.Collection, false
+		rep.Rejected = append([]ImportIssue(nil), rep.Rejected...)
+		rep.Duplicates = append([]ImportIssue(nil), rep.Duplicates...)
+	}
+	skip := rep.Line
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu      sync.Mutex
+		entries = map[int]*importEntry{}
+	)
+	items := make(chan BatchItem)
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(items)
+		dec := codec.NewDecoder(r)
+		seen := map[string]int{}
+		for i, pos := 0, 1; ; pos++ {
+			p, err := dec.Next()
+			if err == io.EOF {
+				readErr <- nil
+				return
+			}
+			e := &importEntry{line: dec.Line(), pos: pos, format: dec.Format(), p: p}
+			var ce *codec.Error
+			switch {
+			case errors.As(err, &ce):
+				e.reject = ce.Msg
+				if ce.Col > 0 {
+					e.reject = fmt.Sprintf("column %d: %s", ce.Col, ce.Msg)
+				}
+			case err != nil:
+				readErr <- err
+				return
+			}
+			if e.line <= skip {
+				continue
+			}
+			if e.reject == "" {
+				e.p.ID = PuzzleID(&e.p.Board)
+				if l, ok := seen[e.p.ID]; ok {
+					e.dupOf = fmt.Sprintf("same as line %d", l)
+				} else if _, err := u.Storage.Load(ctx, e.p.ID); err == nil {
+					e.dupOf = "already stored"
+				} else {
+					seen[e.p.ID] = e.line
+				}
+			}
+			it := BatchItem{Board: domain.Board{Values: e.p.Board.Values}}
+			if e.reject != "" || e.dupOf != "" {
+				it.Err = errSkipped
+			}
+			mu.Lock()
+			entries[i] = e
+			mu.Unlock()
+			select {
+			case items <- it:
+				i++
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// Results complete out of order; hold them until every earlier one is in.
+	pending := map[int]BatchResult{}
+	next, since := 0, 0
+	emit := func(res BatchResult) error {
+		pending[res.Index] = res
+		for {
+			res, ok := pending[next]
+			if !ok {
+				return nil
+			}
+			delete(pending, next)
+			mu.Lock()
+			e := entries[next]
+			delete(entries, next)
+			mu.Unlock()
+			next++
+			if err := u.recordImport(ctx, rep, e, res, &opt); err != nil {
+				return err
+			}
+			if since++; since == checkpointEvery && opt.Checkpoint != nil {
+				since = 0
+				if err := opt.Checkpoint(rep); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	err := u.Batch(ctx, items, opt.Workers, opt.MaxTier, emit)
+	if err == nil {
+		select {
+		case err = <-readErr:
+		default:
+		}
+	}
+	rep.Done = err == nil
+	if opt.Checkpoint != nil {
+		if cerr := opt.Checkpoint(rep); err == nil {
+			err = cerr
+		}
+	}
+	return rep, err
+}
+
+// recordImport saves one puzzle or records why it was left out. Only then
+// does rep.Line move past it, so a resume never skips a puzzle whose save
+// failed. An unnamed puzzle is named after its position in the input, which
+// a resumed import numbers the same way.
+func (u *Service) recordImport(ctx context.Context, rep *ImportReport, e *importEntry, res BatchResult, opt *CollectionOptions) error {
+	if rep.Format == "" {
+		rep.Format = e.format
+	}
+	issue := ImportIssue{Line: e.line, ID: e.p.ID}
+	switch {
+	case e.reject != "":
+		issue.Reason = e.reject
+	case e.dupOf != "":
+		issue.Reason = e.dupOf
+		rep.Duplicates = append(rep.Duplicates, issue)
+		rep.Line = e.line
+		return nil
+	case !res.Valid && res.Error != "":
+		issue.Reason = res.Error
+	case !res.Valid:
+		var cells []string
+		seen := map[domain.CellCoord]bool{}
+		for _, cc := range res.Conflicts {
+			if !seen[cc] {
+				seen[cc] = true
+				cells = append(cells, fmt.Sprintf("r%dc%d", cc.Row+1, cc.Col+1))
+			}
+		}
+		issue.Reason = "givens conflict at " + strings.Join(cells, ", ")
+	case !res.Unique && res.Solution == nil:
+		issue.Reason = "no solution"
+	case !res.Unique:
+		issue.Reason = "more than one solution"
+	case res.Error != "":
+		issue.Reason = res.Error
+	default:
+		p := e.p
+		p.Collection, p.Tags = opt.Collection, opt.Tags
+		p.CreatedAt = time.Now().UnixNano()
+		p.Difficulty = domain.Medium
+		if res.Rating != nil {
+			p.Difficulty = res.Rating.Difficulty()
+		}
+		if p.Name == "" && opt.Collection != "" {
+			p.Name = fmt.Sprintf("%s %d", opt.Collection, e.pos)
+		}
+		if err := u.Save(ctx, &p); err != nil {
+			return err
+		}
+		rep.Imported++
+		rep.Line = e.line
+		return nil
+	}
+	rep.Rejected = append(rep.Rejected, issue)
+	rep.Line = e.line
+	return nil
+}
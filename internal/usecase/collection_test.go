@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"svw.info/sudoku/internal/codec"
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/hint"
+	"svw.info/sudoku/internal/infrastructure/storage"
+	"svw.info/sudoku/internal/solver"
+	"svw.info/sudoku/internal/validator"
+)
+
+func TestImportCollection(t *testing.T) {
+	ctx := context.Background()
+	st := storage.NewFS(t.TempDir())
+	uc := NewService(solver.NewBacktrackingSolver(), nil, validator.New(), hint.NewPipeline(), st)
+
+	b := domain.Board{Values: givens}
+	swapped := b // digits 1 and 2 exchanged: a different puzzle
+	conflict := b
+	conflict.Values[0][2] = 5
+	open := domain.Board{}
+	open.Values[0] = givens[0]
+	for r := range swapped.Values {
+		for c, v := range swapped.Values[r] {
+			switch v {
+			case 1:
+				swapped.Values[r][c] = 2
+			case 2:
+				swapped.Values[r][c] = 1
+			}
+		}
+	}
+	in := strings.Join([]string{
+		codec.Line(&b),
+		codec.Line(&b),
+		codec.Line(&conflict),
+		codec.Line(&b)[:80] + "x",
+		codec.Line(&open),
+		codec.Line(&swapped),
+	}, "\n")
+
+	var checkpoints []ImportReport
+	opt := CollectionOptions{
+		Collection: "classics",
+		Tags:       []string{"test"},
+		MaxTier:    domain.StrategyUniqueness,
+		Workers:    2,
+		Checkpoint: func(r *ImportReport) error {
+			checkpoints = append(checkpoints, *r)
+			return nil
+		},
+	}
+	rep, err := uc.ImportCollection(ctx, strings.NewReader(in), opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rep.Done || rep.Line != 6 || rep.Imported != 2 || rep.Format != codec.FormatLine {
+		t.Fatalf("report = %+v", rep)
+	}
+	if len(rep.Duplicates) != 1 || rep.Duplicates[0].Reason != "same as line 1" {
+		t.Fatalf("duplicates = %+v", rep.Duplicates)
+	}
+	reasons := map[int]string{}
+	for _, is := range rep.Rejected {
+		reasons[is.Line] = is.Reason
+	}
+	if !strings.HasPrefix(reasons[3], "givens conflict") || reasons[4] != "column 81: unexpected 'x'" || reasons[5] != "more than one solution" {
+		t.Fatalf("rejected = %+v", rep.Rejected)
+	}
+	if n := len(checkpoints); n == 0 || !checkpoints[n-1].Done {
+		t.Fatalf("checkpoints = %+v", checkpoints)
+	}
+
+	p, err := st.Load(ctx, PuzzleID(&b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Collection != "classics" || len(p.Tags) != 1 || p.Name != "classics 1" || p.Difficulty != domain.Easy || !p.Board.Fixed[0][0] {
+		t.Fatalf("stored %+v", p)
+	}
+
+	// Resuming after line 5 only looks at the last puzzle, which is stored.
+	opt.Resume, opt.Checkpoint = &ImportReport{Line: 5, Imported: 1}, nil
+	rep, err = uc.ImportCollection(ctx, strings.NewReader(in), opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Imported != 1 || len(rep.Duplicates) != 1 || rep.Duplicates[0].Line != 6 || rep.Duplicates[0].Reason != "already stored" {
+		t.Fatalf("resumed report = %+v", rep)
+	}
+}
+
+// failingStore refuses every save, as a full disk would.
+type failingStore struct{ *storage.FS }
+
+func (failingStore) Save(context.Context, *domain.Puzzle) error { return errors.New("disk full") }
+
+func TestImportCollectionFailedSave(t *testing.T) {
+	ctx := context.Background()
+	uc := NewService(solver.NewBacktrackingSolver(), nil, validator.New(), nil, failingStore{storage.NewFS(t.TempDir())})
+	b := domain.Board{Values: givens}
+	conflict := b
+	conflict.Values[0][2] = 5
+	in := codec.Line(&conflict) + "\n" + codec.Line(&b) + "\n"
+
+	var last ImportReport
+	opt := CollectionOptions{Collection: "c", Workers: 1, Checkpoint: func(r *ImportReport) error {
+		last = *r
+		return nil
+	}}
+	rep, err := uc.ImportCollection(ctx, strings.NewReader(in), opt)
+	// the rejected line 1 is done with; line 2 was never stored
+	if err == nil || rep.Line != 1 || last.Line != 1 || last.Done {
+		t.Fatalf("err = %v, report = %+v, checkpoint = %+v", err, rep, last)
+	}
+}
+
+func TestImportCollectionResumeNames(t *testing.T) {
+	ctx := context.Background()
+	st := storage.NewFS(t.TempDir())
+	uc := NewService(solver.NewBacktrackingSolver(), nil, validator.New(), nil, st)
+	b := domain.Board{Values: givens}
+	open := domain.Board{}
+	in := codec.Line(&open) + "\n" + codec.Line(&b) + "\n"
+
+	// a resume after line 1 names the puzzle on line 2 as the full run would
+	opt := CollectionOptions{Collection: "c", Resume: &ImportReport{Line: 1}}
+	if _, err := uc.ImportCollection(ctx, strings.NewReader(in), opt); err != nil {
+		t.Fatal(err)
+	}
+	p, err := st.Load(ctx, PuzzleID(&b))
+	if err != nil || p.Name != "c 2" {
+		t.Fatalf("stored %+v, %v", p, err)
+	}
+}
@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/ports"
+)
+
+// DailyDateLayout is the date format accepted by the daily endpoints.
+const DailyDateLayout = "2006-01-02"
+
+const dailyPrefix = "daily-"
+
+var errFutureDate = domain.Errorf(domain.KindNotFound, "daily puzzle not available yet")
+
+// Daily serves one puzzle per date and difficulty. The seed is derived from the
+// date, and the first generated puzzle is cached in Storage so every player gets
+// the same board even though generation itself is time-boxed.
+type Daily struct {
+	Generator ports.Generator
+	Storage   ports.Storage
+	// Now is the clock used to resolve "today"; tests replace it.
+	Now func() time.Time
+
+	mu sync.Mutex
+}
+
+// DailyEntry is one archived daily puzzle.
+type DailyEntry struct {
+	Date       string            `json:"date"`
+	Difficulty domain.Difficulty `json:"difficulty"`
+	ID         string            `json:"id"`
+	CreatedAt  int64             `json:"createdAt"`
+}
+
+func NewDaily(g ports.Generator, st ports.Storage) *Daily {
+	return &Daily{Generator: g, Storage: st, Now: time.Now}
+}
+
+// DailySeed derives the deterministic generator seed for a date and difficulty.
+func DailySeed(day time.Time, d domain.Difficulty) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(day.Format(DailyDateLayout) + "/" + d.String()))
+	return int64(h.Sum64() &^ (1 << 63))
+}
+
+func dailyID(day time.Time, d domain.Difficulty) string {
+	return dailyPrefix + day.Format(DailyDateLayout) + "-" + d.String()
+}
+
+func (u *Daily) today() time.Time {
+	now := time.Now
+	if u.Now != nil {
+		now = u.Now
+	}
+	t := now()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Get returns the daily puzzle for date (YYYY-MM-DD, empty means today),
+// generating and storing it on first request.
+func (u *Daily) Get(ctx context.Context, date string, d domain.Difficulty) (*domain.Puzzle, string, error) {
+	if u.Generator == nil || u.Storage == nil {
+		return nil, "", errNotConfigured
+	}
+	today := u.today()
+	day := today
+	if s := strings.TrimSpace(date); s != "" {
+		t, err := time.Parse(DailyDateLayout, s)
+		if err != nil {
+			return nil, "", domain.Errorf(domain.KindInvalid, "invalid date %q: want YYYY-MM-DD", s)
+		}
+		day = t
+	}
+	if day.After(today) {
+		return nil, "", errFutureDate
+	}
+	id := dailyID(day, d)
+
+	// Serialize generation so concurrent first requests produce a single puzzle.
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	p, err := u.Storage.Load(ctx, id)
+	if err == nil {
+		return p, day.Format(DailyDateLayout), nil
+	}
+	if domain.KindOf(err) != domain.KindNotFound {
+		return nil, "", err
+	}
+	p, _, err = u.Generator.Generate(ctx, DailySeed(day, d), d)
+	if err != nil {
+		return nil, "", err
+	}
+	p.ID = id
+	p.Difficulty = d
+	p.Name = fmt.Sprintf("Daily %s (%s)", day.Format(DailyDateLayout), d)
+	if err := u.Storage.Save(ctx, p); err != nil {
+		return nil, "", err
+	}
+	return p, day.Format(DailyDateLayout), nil
+}
+
+// Archive lists stored dailies up to today, newest first.
+func (u *Daily) Archive(ctx context.Context) ([]DailyEntry, error) {
+	if u.Storage == nil {
+		return nil, errNotConfigured
+	}
+	metas, err := u.Storage.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	today := u.today()
+	out := make([]DailyEntry, 0, len(metas))
+	for _, m := range metas {
+		if !strings.HasPrefix(m.ID, dailyPrefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(m.ID, dailyPrefix)
+		if len(rest) < len(DailyDateLayout) {
+			continue
+		}
+		day, err := time.Parse(DailyDateLayout, rest[:len(DailyDateLayout)])
+		if err != nil || day.After(today) {
+			continue
+		}
+		out = append(out, DailyEntry{
+			Date:       day.Format(DailyDateLayout),
+			Difficulty: m.Difficulty,
+			ID:         m.ID,
+			CreatedAt:  m.CreatedAt,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Date != out[j].Date {
+			return out[i].Date > out[j].Date
+		}
+		return out[i].Difficulty < out[j].Difficulty
+	})
+	return out, nil
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/infrastructure/storage"
+	"svw.info/sudoku/internal/ports"
+)
+
+// countingGen returns a board derived from the seed and counts calls.
+type countingGen struct{ calls int }
+
+func (g *countingGen) Generate(ctx context.Context, seed int64, d domain.Difficulty) (*domain.Puzzle, ports.Stats, error) {
+	g.calls++
+	var b domain.Board
+	b.Values[0][0] = uint8(seed%9) + 1
+	return &domain.Puzzle{Seed: seed, Difficulty: d, Board: b, CreatedAt: 1}, ports.Stats{}, nil
+}
+
+func TestDailyCachedAndDeterministic(t *testing.T) {
+	ctx := context.Background()
+	gen := &countingGen{}
+	d := NewDaily(gen, storage.NewFS(t.TempDir()))
+	d.Now = func() time.Time { return time.Date(2026, 3, 14, 18, 30, 0, 0, time.UTC) }
+
+	p1, date, err := d.Get(ctx, "", domain.Hard)
+	if err != nil {
+		t.Fatalf("Get today: %v", err)
+	}
+	if date != "2026-03-14" {
+		t.Fatalf("today resolved to %q", date)
+	}
+	p2, _, err := d.Get(ctx, "2026-03-14", domain.Hard)
+	if err != nil {
+		t.Fatalf("Get by date: %v", err)
+	}
+	if gen.calls != 1 {
+		t.Fatalf("expected one generation, got %d", gen.calls)
+	}
+	if p1.ID != p2.ID || p1.Board != p2.Board {
+		t.Fatalf("daily differs between calls: %v vs %v", p1.ID, p2.ID)
+	}
+	want := DailySeed(time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC), domain.Hard)
+	if p1.Seed != want {
+		t.Fatalf("seed = %d, want %d", p1.Seed, want)
+	}
+	if DailySeed(time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC), domain.Hard) == want {
+		t.Fatalf("consecutive days share a seed")
+	}
+
+	if _, _, err := d.Get(ctx, "2026-03-15", domain.Hard); err == nil {
+		t.Fatalf("expected future date to be rejected")
+	}
+	if _, _, err := d.Get(ctx, "14.03.2026", domain.Hard); err == nil {
+		t.Fatalf("expected malformed date to be rejected")
+	}
+}
+
+func TestDailyArchive(t *testing.T) {
+	ctx := context.Background()
+	d := NewDaily(&countingGen{}, storage.NewFS(t.TempDir()))
+	now := time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)
+	d.Now = func() time.Time { return now }
+
+	for _, day := range []string{"2026-03-12", "2026-03-14", "2026-03-13"} {
+		if _, _, err := d.Get(ctx, day, domain.Easy); err != nil {
+			t.Fatalf("Get %s: %v", day, err)
+		}
+	}
+	if _, _, err := d.Get(ctx, "2026-03-13", domain.Expert); err != nil {
+		t.Fatalf("Get expert: %v", err)
+	}
+
+	// Rewind the clock: entries after "today" must not be listed.
+	now = time.Date(2026, 3, 13, 0, 0, 0, 0, time.UTC)
+	got, err := d.Archive(ctx)
+	if err != nil {
+		t.Fatalf("Archive: %v", err)
+	}
+	want := []struct {
+		date string
+		diff domain.Difficulty
+	}{
+		{"2026-03-13", domain.Easy},
+		{"2026-03-13", domain.Expert},
+		{"2026-03-12", domain.Easy},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("archive = %+v, want %d entries", got, len(want))
+	}
+	for i, w := range want {
+		if got[i].Date != w.date || got[i].Difficulty != w.diff {
+			t.Fatalf("archive[%d] = %+v, want %s/%s", i, got[i], w.date, w.diff)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"svw.info/sudoku/internal/domain"
+)
+
+// Page sizes of FindPuzzles.
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+// ErrBadCursor reports a cursor that FindPuzzles did not hand out.
+var ErrBadCursor = domain.Errorf(domain.KindInvalid, "invalid cursor")
+
+// PuzzleQuery filters and pages the stored puzzles.
+type PuzzleQuery struct {
+	Difficulty *domain.Difficulty // nil matches every difficulty
+	Text       string             // case-insensitive substring of the ID, name, collection or a tag
+	Limit      int                // page size; 0 means DefaultPageSize, capped at MaxPageSize
+	Cursor     string             // Next of the previous page; "" starts at the top
+}
+
+// PuzzlePage is one page of matching puzzles, newest first.
+type PuzzlePage struct {
+	Puzzles []domain.PuzzleMeta
+	Next    string // cursor of the following page; "" on the last one
+}
+
+// FindPuzzles lists the stored puzzles matching q, newest first. The cursor
+// names the last puzzle of a page rather than an offset, so saving puzzles
+// between requests neither repeats nor skips older ones.
+func (u *Service) FindPuzzles(ctx context.Context, q PuzzleQuery) (*PuzzlePage, error) {
+	if u.Storage == nil {
+		return nil, errNotConfigured
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	limit = min(limit, MaxPageSize)
+	var after *domain.PuzzleMeta
+	if q.Cursor != "" {
+		m, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = &m
+	}
+
+	all, err := u.Storage.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(all, func(i, j int) bool { return newer(&all[i], &all[j]) })
+	text := strings.ToLower(strings.TrimSpace(q.Text))
+	page := &PuzzlePage{Puzzles: []domain.PuzzleMeta{}}
+	for i := range all {
+		m := &all[i]
+		if after != nil && !newer(after, m) {
+			continue
+		}
+		if (q.Difficulty != nil && m.Difficulty != *q.Difficulty) || !matches(m, text) {
+			continue
+		}
+		if len(page.Puzzles) == limit {
+			page.Next = encodeCursor(&page.Puzzles[limit-1])
+			break
+		}
+		page.Puzzles = append(page.Puzzles, *m)
+	}
+	return page, nil
+}
+
+// newer orders puzzles by creation time, latest first, then by ID.
+func newer(a, b *domain.PuzzleMeta) bool {
+	if a.CreatedAt != b.CreatedAt {
+		return a.CreatedAt > b.CreatedAt
+	}
+	return a.ID < b.ID
+}
+
+func matches(m *domain.PuzzleMeta, text string) bool {
+	if text == "" {
+		return true
+	}
+	fields := append([]string{m.ID, m.Name, m.Collection}, m.Tags...)
+	for _, f := range fields {
+		if strings.Contains(strings.ToLower(f), text) {
+			return true
+		}
+	}
+	return false
+}
+
+func encodeCursor(m *domain.PuzzleMeta) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d/%s", m.CreatedAt, m.ID)))
+}
+
+func decodeCursor(s string) (domain.PuzzleMeta, error) {
+	var m domain.PuzzleMeta
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return m, ErrBadCursor
+	}
+	created, id, ok := strings.Cut(string(raw), "/")
+	if _, err := fmt.Sscan(created, &m.CreatedAt); err != nil || !ok || id == "" {
+		return m, ErrBadCursor
+	}
+	m.ID = id
+	return m, nil
+}
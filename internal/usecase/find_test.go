@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/infrastructure/storage"
+)
+
+func TestFindPuzzles(t *testing.T) {
+	ctx := context.Background()
+	uc := NewService(nil, nil, nil, nil, storage.NewFS(t.TempDir()))
+	for i := 0; i < 7; i++ {
+		p := &domain.Puzzle{ID: fmt.Sprintf("p%d", i), CreatedAt: int64(100 + i), Difficulty: domain.Difficulty(i % 2)}
+		if i == 3 {
+			p.Name, p.Tags = "Sunday", []string{"club"}
+		}
+		if err := uc.Save(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var ids []string
+	q := PuzzleQuery{Limit: 3}
+	for pages := 0; ; pages++ {
+		page, err := uc.FindPuzzles(ctx, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range page.Puzzles {
+			ids = append(ids, m.ID)
+		}
+		if page.Next == "" {
+			if pages != 2 {
+				t.Errorf("got %d further pages, want 2", pages)
+			}
+			break
+		}
+		// A puzzle saved meanwhile sorts first and does not shift later pages.
+		if err := uc.Save(ctx, &domain.Puzzle{ID: fmt.Sprintf("new%d", pages), CreatedAt: 1000}); err != nil {
+			t.Fatal(err)
+		}
+		q.Cursor = page.Next
+	}
+	if got := fmt.Sprint(ids); got != "[p6 p5 p4 p3 p2 p1 p0]" {
+		t.Errorf("pages = %s", got)
+	}
+
+	medium := domain.Medium
+	page, err := uc.FindPuzzles(ctx, PuzzleQuery{Difficulty: &medium, Text: "CLUB"})
+	if err != nil || len(page.Puzzles) != 1 || page.Puzzles[0].ID != "p3" {
+		t.Errorf("filtered page = %+v, %v", page, err)
+	}
+	if _, err := uc.FindPuzzles(ctx, PuzzleQuery{Cursor: "!!"}); !errors.Is(err, ErrBadCursor) {
+		t.Errorf("bad cursor: err = %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/replay"
+)
+
+// StartGame opens a new session on a stored puzzle. When puzzleID is empty the
+// given board is saved as a new puzzle first so the game can reference it.
+func (u *Service) StartGame(ctx context.Context, puzzleID string, b *domain.Board) (*domain.Game, error) {
+	if u.Storage == nil {
+		return nil, errNotConfigured
+	}
+	now := time.Now().UnixNano()
+	var p *domain.Puzzle
+	if puzzleID != "" {
+		var err error
+		if p, err = u.Storage.Load(ctx, puzzleID); err != nil {
+			return nil, err
+		}
+	} else {
+		if b == nil {
+			return nil, domain.Errorf(domain.KindInvalid, "missing puzzle id or board")
+		}
+		p = &domain.Puzzle{ID: strconv.FormatInt(now, 10), Board: *b, CreatedAt: now}
+		if err := u.Storage.Save(ctx, p); err != nil {
+			return nil, err
+		}
+	}
+	g := domain.NewGame("g"+strconv.FormatInt(now, 10), p, now)
+	if err := u.Storage.SaveGame(ctx, g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Game loads a session by id.
+func (u *Service) Game(ctx context.Context, id string) (*domain.Game, error) {
+	if u.Storage == nil {
+		return nil, errNotConfigured
+	}
+	return u.Storage.LoadGame(ctx, id)
+}
+
+// PlayMove timestamps m, appends it to the game's log and persists the result.
+// Undo and redo are recorded as moves too, so the log stays append-only.
+func (u *Service) PlayMove(ctx context.Context, id string, m domain.Move) (*domain.Game, error) {
+	if u.Storage == nil {
+		return nil, errNotConfigured
+	}
+	u.gameMu.Lock()
+	defer u.gameMu.Unlock()
+	g, err := u.Storage.LoadGame(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	m.At = time.Now().UnixNano()
+	if err := g.Play(m); err != nil {
+		return nil, err
+	}
+	if err := u.Storage.SaveGame(ctx, g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Replay returns the timed move log of a game with its analytics.
+func (u *Service) Replay(ctx context.Context, id string) (*replay.Replay, error) {
+	g, err := u.Game(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	rp := replay.Build(g)
+	return &rp, nil
+}
@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/infrastructure/storage"
+)
+
+func TestGamePersistsMovesUndoAndRedo(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	uc := NewService(nil, nil, nil, nil, storage.NewFS(dir))
+	// reload reads a game back through a fresh store over the same directory.
+	reload := func(id string) *domain.Game {
+		t.Helper()
+		g, err := NewService(nil, nil, nil, nil, storage.NewFS(dir)).Game(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return g
+	}
+
+	g, err := uc.StartGame(ctx, "", fixedBoard())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, err := uc.Load(ctx, g.PuzzleID); err != nil || p.Board.Values != givens {
+		t.Fatalf("puzzle saved for the game = %+v, %v", p, err)
+	}
+	if _, err := uc.StartGame(ctx, "", nil); domain.KindOf(err) != domain.KindInvalid {
+		t.Errorf("StartGame without a puzzle: %v", err)
+	}
+	if _, err := uc.StartGame(ctx, "missing", nil); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("StartGame on a missing puzzle: %v", err)
+	}
+	g2, err := uc.StartGame(ctx, g.PuzzleID, nil)
+	if err != nil || g2.PuzzleID != g.PuzzleID || g2.Board.Values != givens {
+		t.Fatalf("StartGame on a stored puzzle = %+v, %v", g2, err)
+	}
+	if got := reload(g.ID); got.Board.Values != givens || len(got.Moves) != 0 {
+		t.Errorf("reloaded new game = %+v", got)
+	}
+	id := g2.ID
+
+	for _, m := range []domain.Move{
+		{Kind: domain.MoveSet, Row: 0, Col: 2, Value: 4},
+		{Kind: domain.MoveNote, Row: 0, Col: 3, Value: 6},
+		{Kind: domain.MoveUndo},
+	} {
+		if _, err := uc.PlayMove(ctx, id, m); err != nil {
+			t.Fatalf("%+v: %v", m, err)
+		}
+	}
+	// A rejected move is not written.
+	if _, err := uc.PlayMove(ctx, id, domain.Move{Kind: domain.MoveSet, Row: 0, Col: 0, Value: 1}); !errors.Is(err, domain.ErrFixedCell) {
+		t.Errorf("move on a given: %v", err)
+	}
+	if _, err := uc.PlayMove(ctx, "missing", domain.Move{Kind: domain.MoveUndo}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("move on a missing game: %v", err)
+	}
+
+	got := reload(id)
+	if len(got.Moves) != 3 || got.Board.Values[0][2] != 4 || got.Marks.Has(0, 3, 6) {
+		t.Fatalf("after undo: moves %+v, r1c3 %d, r1c4 marks %v", got.Moves, got.Board.Values[0][2], got.Marks[0][3])
+	}
+	if applied, redo := got.History(); len(applied) != 1 || len(redo) != 1 {
+		t.Errorf("History after undo = %v, %v", applied, redo)
+	}
+	for _, m := range got.Moves {
+		if m.At == 0 {
+			t.Errorf("move %+v has no timestamp", m)
+		}
+	}
+
+	if _, err := uc.PlayMove(ctx, id, domain.Move{Kind: domain.MoveRedo}); err != nil {
+		t.Fatal(err)
+	}
+	got = reload(id)
+	if !got.Marks.Has(0, 3, 6) || got.Board.Values[0][2] != 4 {
+		t.Errorf("after redo: r1c3 %d, r1c4 marks %v", got.Board.Values[0][2], got.Marks[0][3])
+	}
+	if _, err := uc.PlayMove(ctx, id, domain.Move{Kind: domain.MoveRedo}); !errors.Is(err, domain.ErrNothingRedo) {
+		t.Errorf("second redo: %v", err)
+	}
+}
@@ -0,0 +1,240 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/hint"
+)
+
+// hintState remembers the last step hinted to a player and how far it was disclosed.
+type hintState struct {
+	key   string
+	level domain.HintLevel
+	used  int
+	seen  time.Time
+}
+
+const (
+	maxHintSessions = 1024
+	hintSessionTTL  = time.Hour
+)
+
+// GradedHint returns the next logical step, disclosed one level further each
+// time the same step is requested again for session: technique, then house,
+// then cells, then the digit. A different step starts over at a nudge; a
+// positive level forces that level. Every call counts as a hint used; when
+// gameID is set the count is kept on the game so it survives for scoring.
+// Without a session or game nothing is remembered: each call is disclosed at
+// level, a nudge when that is 0, and is not counted.
+//
+// With cands set, strategies run on the player's pencil marks; if those marks
+// are inconsistent with the true candidates, that is reported first, in full
+// and without counting as a hint. With fallback set, LastResort steps in when
+// no strategy up to max applies.
+func (u *Service) GradedHint(ctx context.Context, session, gameID string, b *domain.Board, cands *domain.Marks, max domain.StrategyTier, level domain.HintLevel, fallback bool) (domain.Hint, bool, int, error) {
+	var (
+		hh  domain.Hint
+		ok  bool
+		err error
+	)
+	if cands != nil {
+		if nh, bad := u.notesHint(ctx, b, cands); bad {
+			return nh, true, 0, nil
+		}
+		hh, ok, err = u.HintFrom(ctx, b, cands, max)
+	} else {
+		hh, ok, err = u.Hint(ctx, b, max)
+	}
+	if err == nil && !ok && fallback {
+		hh, ok, err = u.LastResort(ctx, b, cands)
+	}
+	if err != nil || !ok {
+		return domain.Hint{}, ok, 0, err
+	}
+	if gameID != "" {
+		session = "game:" + gameID
+	}
+	if session == "" {
+		switch {
+		case hh.Technique == revealTechnique:
+			level = domain.HintAnswer
+		case level <= 0:
+			level = domain.HintNudge
+		}
+		return hh.Disclose(level), true, 0, nil
+	}
+
+	u.hintMu.Lock()
+	if u.hints == nil {
+		u.hints = map[string]*hintState{}
+	}
+	now := time.Now()
+	st := u.hints[session]
+	if st == nil {
+		if len(u.hints) >= maxHintSessions {
+			u.evictHintSessions(now)
+		}
+		st = &hintState{}
+		u.hints[session] = st
+	}
+	key := hh.Key()
+	switch {
+	case hh.Technique == revealTechnique:
+		st.level = domain.HintAnswer // nothing to work out
+	case level > 0:
+		st.level = level
+	case st.key == key && st.level < domain.HintAnswer:
+		st.level++
+	case st.key != key:
+		st.level = domain.HintNudge
+	}
+	st.key = key
+	st.used++
+	st.seen = now
+	disclosed, used := hh.Disclose(st.level), st.used
+	u.hintMu.Unlock()
+
+	if gameID != "" && u.Storage != nil {
+		u.gameMu.Lock()
+		defer u.gameMu.Unlock()
+		g, err := u.Storage.LoadGame(ctx, gameID)
+		if err != nil {
+			return domain.Hint{}, false, 0, err
+		}
+		g.HintsUsed++
+		if err := u.Storage.SaveGame(ctx, g); err != nil {
+			return domain.Hint{}, false, 0, err
+		}
+		used = g.HintsUsed
+	}
+	return disclosed, true, used, nil
+}
+
+// evictHintSessions drops the sessions idle for longer than hintSessionTTL,
+// or the least recently seen one when none is, so that new sessions never
+// grow the map past maxHintSessions. hintMu must be held.
+func (u *Service) evictHintSessions(now time.Time) {
+	var oldest string
+	for k, st := range u.hints {
+		if now.Sub(st.seen) > hintSessionTTL {
+			delete(u.hints, k)
+		} else if oldest == "" || st.seen.Before(u.hints[oldest].seen) {
+			oldest = k
+		}
+	}
+	if len(u.hints) >= maxHintSessions {
+		delete(u.hints, oldest)
+	}
+}
+
+// notesHint describes the first problem with the player's pencil marks: a
+// solution digit that was erased, else marks a placed peer rules out. Without
+// a unique solution only the latter can be checked.
+func (u *Service) notesHint(ctx context.Context, b *domain.Board, cands *domain.Marks) (domain.Hint, bool) {
+	rep, err := u.CheckMarks(ctx, b, cands)
+	if err != nil {
+		rep = hint.CheckMarks(b, cands, nil)
+	}
+	if n := len(rep.Missing); n > 0 {
+		m := rep.Missing[0]
+		msg := fmt.Sprintf("Your notes in r%dc%d are missing a candidate that is still needed", m.Row+1, m.Col+1)
+		if n > 1 {
+			msg += fmt.Sprintf(" (and %d more cells)", n-1)
+		}
+		cells := make([]domain.CellCoord, 0, n)
+		for _, c := range rep.Missing {
+			cells = append(cells, domain.CellCoord{Row: c.Row, Col: c.Col})
+		}
+		return domain.Hint{Message: msg, Cells: cells, Technique: notesTechnique, Level: domain.HintCells}, true
+	}
+	if n := len(rep.Impossible); n > 0 {
+		m := rep.Impossible[0]
+		msg := fmt.Sprintf("Remove %d from r%dc%d: a placed peer already holds it", m.Digit, m.Row+1, m.Col+1)
+		if n > 1 {
+			msg += fmt.Sprintf(" (%d impossible notes in total)", n)
+		}
+		cells := make([]domain.CellCoord, 0, n)
+		for _, c := range rep.Impossible {
+			cells = append(cells, domain.CellCoord{Row: c.Row, Col: c.Col})
+		}
+		return domain.Hint{Message: msg, Cells: cells, Technique: notesTechnique, Eliminations: rep.Impossible, Level: domain.HintAnswer}, true
+	}
+	return domain.Hint{}, false
+}
+
+const (
+	notesTechnique  = "inconsistent notes"
+	entryTechnique  = "wrong entry"
+	revealTechnique = "revealed digit"
+	forcingDepth    = 12 // singles placed per forcing chain
+)
+
+// LastResort is the fallback when no strategy applies. The solution comes
+// from the givens alone, as in CheckMarks, so the player's entries can
+// neither make it fail nor choose it; an entry that disagrees with it is
+// pointed out first. Otherwise LastResort looks for a cell forced by a short
+// chain of singles ending in a contradiction, and failing that reveals the
+// solution digit of the cell with the fewest candidates, counted on cands
+// when given. The technique tells which of these happened.
+func (u *Service) LastResort(ctx context.Context, b *domain.Board, cands *domain.Marks) (domain.Hint, bool, error) {
+	var sol *domain.Board
+	var err error = errNotConfigured
+	if u.Solver != nil && u.Validator != nil {
+		givens, n := givensOf(b)
+		if n == 0 {
+			givens = domain.Board{Values: b.Values}
+		}
+		if sol, err = u.uniqueSolution(ctx, &givens); err == nil {
+			if hh, ok := wrongEntry(b, sol); ok {
+				return hh, true, nil
+			}
+		}
+	}
+	if hh, ok := hint.Forced(b, cands, forcingDepth); ok {
+		return hh, true, nil
+	}
+	if err != nil {
+		return domain.Hint{}, false, err
+	}
+	g, _ := domain.NewGrid(b)
+	if cands != nil {
+		g, _ = domain.GridFromMarks(b, cands)
+	}
+	cell := g.BestCell()
+	if cell < 0 {
+		return domain.Hint{}, false, nil
+	}
+	cc := domain.Coord(cell)
+	v := sol.Values[cc.Row][cc.Col]
+	return domain.Hint{
+		Message: fmt.Sprintf("No logical step or short forcing chain found; revealing r%dc%d, the cell with the fewest candidates: it is %d",
+			cc.Row+1, cc.Col+1, v),
+		Cells:     []domain.CellCoord{cc},
+		Strategy:  domain.StrategyLastResort,
+		Technique: revealTechnique,
+		House:     &domain.House{Kind: domain.HouseBox, Index: (cc.Row/3)*3 + cc.Col/3},
+		Digit:     v,
+	}, true, nil
+}
+
+// wrongEntry points out the first entry of b that disagrees with sol.
+func wrongEntry(b, sol *domain.Board) (domain.Hint, bool) {
+	for r := 0; r < 9; r++ {
+		for c := 0; c < 9; c++ {
+			if v := b.Values[r][c]; v != 0 && v != sol.Values[r][c] {
+				return domain.Hint{
+					Message:   fmt.Sprintf("r%dc%d holds %d, but the solution has %d there", r+1, c+1, v, sol.Values[r][c]),
+					Cells:     []domain.CellCoord{{Row: r, Col: c}},
+					Strategy:  domain.StrategyLastResort,
+					Technique: entryTechnique,
+					House:     &domain.House{Kind: domain.HouseBox, Index: (r/3)*3 + c/3},
+					Digit:     sol.Values[r][c],
+				}, true
+			}
+		}
+	}
+	return domain.Hint{}, false
+}
@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/hint"
+	"svw.info/sudoku/internal/solver"
+	"svw.info/sudoku/internal/validator"
+)
+
+func TestGradedHintEscalates(t *testing.T) {
+	ctx := context.Background()
+	uc := NewService(nil, nil, nil, hint.NewSingles(), nil)
+	b := &domain.Board{Values: givens}
+
+	want := []domain.HintLevel{domain.HintNudge, domain.HintHouse, domain.HintCells, domain.HintAnswer, domain.HintAnswer}
+	for i, lvl := range want {
+		hh, ok, used, err := uc.GradedHint(ctx, "s1", "", b, nil, domain.StrategySingles, 0, false)
+		if err != nil || !ok {
+			t.Fatalf("call %d: ok=%v err=%v", i, ok, err)
+		}
+		if hh.Level != lvl || used != i+1 {
+			t.Fatalf("call %d: level=%d used=%d, want level %d", i, hh.Level, used, lvl)
+		}
+		switch lvl {
+		case domain.HintNudge:
+			if hh.House != nil || len(hh.Cells) != 0 || hh.Digit != 0 {
+				t.Fatalf("nudge discloses too much: %+v", hh)
+			}
+		case domain.HintCells:
+			if len(hh.Cells) == 0 || hh.Digit != 0 {
+				t.Fatalf("cells level: %+v", hh)
+			}
+		case domain.HintAnswer:
+			if hh.Digit == 0 {
+				t.Fatalf("answer level without digit: %+v", hh)
+			}
+		}
+	}
+
+	// Another session starts at a nudge; a different step resets the ladder.
+	if hh, _, _, _ := uc.GradedHint(ctx, "s2", "", b, nil, domain.StrategySingles, 0, false); hh.Level != domain.HintNudge {
+		t.Fatalf("new session level = %d", hh.Level)
+	}
+	full, _, _ := uc.Hint(ctx, b, domain.StrategySingles)
+	b.Values[full.Cells[0].Row][full.Cells[0].Col] = full.Digit
+	if hh, _, _, _ := uc.GradedHint(ctx, "s1", "", b, nil, domain.StrategySingles, 0, false); hh.Level != domain.HintNudge {
+		t.Fatalf("new step level = %d", hh.Level)
+	}
+	if hh, _, _, _ := uc.GradedHint(ctx, "s1", "", b, nil, domain.StrategySingles, domain.HintAnswer, false); hh.Digit == 0 {
+		t.Fatalf("forced answer level without digit: %+v", hh)
+	}
+}
+
+func TestGradedHintAnonymous(t *testing.T) {
+	ctx := context.Background()
+	uc := NewService(nil, nil, nil, hint.NewSingles(), nil)
+	b := &domain.Board{Values: givens}
+
+	// Callers without a session share nothing: every call is a fresh nudge.
+	for i := 0; i < 3; i++ {
+		hh, ok, used, err := uc.GradedHint(ctx, "", "", b, nil, domain.StrategySingles, 0, false)
+		if err != nil || !ok || hh.Level != domain.HintNudge || used != 0 {
+			t.Fatalf("call %d: level=%d used=%d ok=%v err=%v", i, hh.Level, used, ok, err)
+		}
+	}
+	if hh, _, _, _ := uc.GradedHint(ctx, "", "", b, nil, domain.StrategySingles, domain.HintCells, false); hh.Level != domain.HintCells {
+		t.Fatalf("requested level ignored: %d", hh.Level)
+	}
+	if len(uc.hints) != 0 {
+		t.Fatalf("anonymous calls tracked: %v", uc.hints)
+	}
+}
+
+func TestHintSessionsAreCapped(t *testing.T) {
+	ctx := context.Background()
+	uc := NewService(nil, nil, nil, hint.NewSingles(), nil)
+	b := &domain.Board{Values: givens}
+	for i := 0; i < maxHintSessions+10; i++ {
+		if _, _, _, err := uc.GradedHint(ctx, fmt.Sprint("s", i), "", b, nil, domain.StrategySingles, 0, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(uc.hints) != maxHintSessions {
+		t.Fatalf("%d sessions tracked, want at most %d", len(uc.hints), maxHintSessions)
+	}
+	if _, ok := uc.hints["s0"]; ok {
+		t.Fatal("the oldest session survived")
+	}
+	if _, ok := uc.hints[fmt.Sprint("s", maxHintSessions+9)]; !ok {
+		t.Fatal("the newest session was evicted")
+	}
+}
+
+func TestGradedHintFromNotes(t *testing.T) {
+	ctx := context.Background()
+	uc := NewService(solver.NewDLXSolver(), nil, validator.New(), hint.NewSingles(), nil)
+	b := fixedBoard()
+
+	// Notes that dropped the solution digit (4) are reported before any strategy.
+	var marks domain.Marks
+	marks.Toggle(0, 2, 1)
+	marks.Toggle(0, 2, 2)
+	hh, ok, used, err := uc.GradedHint(ctx, "n1", "", b, &marks, domain.StrategySingles, 0, false)
+	if err != nil || !ok || hh.Technique != notesTechnique || used != 0 {
+		t.Fatalf("expected notes report, got %+v ok=%v used=%d err=%v", hh, ok, used, err)
+	}
+
+	// Consistent notes drive the strategies: narrowing r1c3 to 4 makes it a naked single.
+	marks = domain.Marks{}
+	marks.Toggle(0, 2, 4)
+	hh, ok, _, err = uc.GradedHint(ctx, "n1", "", b, &marks, domain.StrategySingles, domain.HintAnswer, false)
+	if err != nil || !ok {
+		t.Fatalf("GradedHint: ok=%v err=%v", ok, err)
+	}
+	if hh.Technique != "naked single" || hh.Cells[0] != (domain.CellCoord{Row: 0, Col: 2}) || hh.Digit != 4 {
+		t.Fatalf("hint ignored notes: %+v", hh)
+	}
+}
+
+func TestGradedHintFallback(t *testing.T) {
+	ctx := context.Background()
+	uc := NewService(solver.NewBacktrackingSolver(), nil, validator.New(), hint.NewSingles(), nil)
+	// AI Escargot, solved with singles until they run out.
+	rows := []string{
+		"100007090", "030020008", "009600500",
+		"005300900", "010080002", "600004000",
+		"300000010", "040000007", "007000300",
+	}
+	b := &domain.Board{}
+	for r, row := range rows {
+		for c, ch := range row {
+			b.Values[r][c] = uint8(ch - '0')
+		}
+	}
+	for {
+		hh, ok, err := uc.Hint(ctx, b, domain.StrategySingles)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			break
+		}
+		b.Values[hh.Cells[0].Row][hh.Cells[0].Col] = hh.Digit
+	}
+	if _, ok, _, err := uc.GradedHint(ctx, "f1", "", b, nil, domain.StrategySingles, 0, false); err != nil || ok {
+		t.Fatalf("expected no hint without fallback: ok=%v err=%v", ok, err)
+	}
+	hh, ok, _, err := uc.GradedHint(ctx, "f1", "", b, nil, domain.StrategySingles, domain.HintAnswer, true)
+	if err != nil || !ok || hh.Strategy != domain.StrategyLastResort {
+		t.Fatalf("fallback hint = %+v ok=%v err=%v", hh, ok, err)
+	}
+	if hh.Technique != hint.ForcingTechnique && hh.Technique != revealTechnique {
+		t.Fatalf("fallback must say what it did, got %q", hh.Technique)
+	}
+	sol, _, _ := uc.Solver.Solve(ctx, b)
+	if c := hh.Cells[0]; sol.Values[c.Row][c.Col] != hh.Digit {
+		t.Fatalf("fallback digit %d at %v disagrees with solution %d", hh.Digit, c, sol.Values[c.Row][c.Col])
+	}
+}
+
+func TestLastResortSolvesFromGivens(t *testing.T) {
+	ctx := context.Background()
+	uc := NewService(solver.NewDLXSolver(), nil, validator.New(), nil, nil)
+	b := fixedBoard()
+	sol, _, err := uc.Solver.Solve(ctx, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An entry that clashes with no peer but is not the solution's digit:
+	// solving the board with it would fail, so it must be pointed out.
+	g, _ := domain.NewGrid(b)
+	var wrong domain.CellCoord
+	for cell := 0; cell < 81; cell++ {
+		cc := domain.Coord(cell)
+		if others := g.Cands[cell].Without(sol.Values[cc.Row][cc.Col]); others != 0 {
+			b.Values[cc.Row][cc.Col], wrong = others.List()[0], cc
+			break
+		}
+	}
+	hh, ok, err := uc.LastResort(ctx, b, nil)
+	if err != nil || !ok || hh.Technique != entryTechnique || hh.Cells[0] != wrong || hh.Digit != sol.Values[wrong.Row][wrong.Col] {
+		t.Fatalf("hint = %+v ok=%v err=%v, want wrong entry at %v", hh, ok, err, wrong)
+	}
+
+	// With the entry corrected, the revealed digit is the solution's, in the
+	// cell with the fewest candidates among the player's notes.
+	b.Values[wrong.Row][wrong.Col] = sol.Values[wrong.Row][wrong.Col]
+	var marks domain.Marks
+	var last domain.CellCoord
+	for cell := 0; cell < 81; cell++ {
+		if cc := domain.Coord(cell); b.Values[cc.Row][cc.Col] == 0 {
+			marks[cc.Row][cc.Col], last = domain.AllDigits, cc
+		}
+	}
+	v := sol.Values[last.Row][last.Col]
+	marks[last.Row][last.Col] = domain.DigitsOf(v, v%9+1)
+	hh, ok, err = uc.LastResort(ctx, b, &marks)
+	if err != nil || !ok || hh.Technique != revealTechnique || hh.Cells[0] != last || hh.Digit != v {
+		t.Fatalf("hint = %+v ok=%v err=%v", hh, ok, err)
+	}
+}
@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+	"io"
+
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/pdf"
+)
+
+// Print writes a PDF booklet of the stored puzzles with the given IDs, or
+// of every stored puzzle when ids is empty; see PrintPuzzles.
+func (u *Service) Print(ctx context.Context, ids []string, opt pdf.Options, w io.Writer) error {
+	if u.Storage == nil {
+		return errNotConfigured
+	}
+	ps, err := u.loadAll(ctx, ids)
+	if err != nil {
+		return err
+	}
+	return u.PrintPuzzles(ctx, ps, opt, w)
+}
+
+// PrintPuzzles writes a PDF booklet of ps with an answer key solved from
+// their givens. A puzzle without a solution prints without an answer.
+func (u *Service) PrintPuzzles(ctx context.Context, ps []domain.Puzzle, opt pdf.Options, w io.Writer) error {
+	if u.Solver == nil {
+		return errNotConfigured
+	}
+	entries := make([]pdf.Entry, len(ps))
+	for i := range ps {
+		entries[i].Puzzle = ps[i]
+		givens := ps[i].Givens()
+		if sol, _, err := u.Solver.Solve(ctx, &givens); err == nil {
+			entries[i].Solution = sol
+		} else if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	return pdf.Booklet(w, entries, opt)
+}
@@ -2,9 +2,11 @@ package usecase
 
 import (
 	"context"
-	"errors"
+	"sync"
+	"time"
 
 	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/hint"
 	"svw.info/sudoku/internal/ports"
 )
 
@@ -14,13 +16,17 @@ type Service struct {
 	Validator ports.Validator
 	Hinter    ports.Hinter
 	Storage   ports.Storage
+
+	gameMu sync.Mutex // serializes load-modify-save of game sessions
+	hintMu sync.Mutex
+	hints  map[string]*hintState // graded-hint progress per session
 }
 
 func NewService(s ports.Solver, g ports.Generator, v ports.Validator, h ports.Hinter, st ports.Storage) *Service {
 	return &Service{Solver: s, Generator: g, Validator: v, Hinter: h, Storage: st}
 }
 
-var errNotConfigured = errors.New("usecase dependency not configured")
+var errNotConfigured = domain.Errorf(domain.KindNotImplemented, "usecase dependency not configured")
 
 func (u *Service) Solve(ctx context.Context, b *domain.Board) (*domain.Board, ports.Stats, error) {
 	if u.Solver == nil {
@@ -43,6 +49,14 @@ func (u *Service) Validate(ctx context.Context, b *domain.Board) (bool, []domain
 	return u.Validator.Validate(ctx, b)
 }
 
+// Contradictions reports structural dead ends on b without solving it.
+func (u *Service) Contradictions(ctx context.Context, b *domain.Board) ([]domain.Contradiction, error) {
+	if u.Validator == nil {
+		return nil, errNotConfigured
+	}
+	return u.Validator.Contradictions(ctx, b)
+}
+
 func (u *Service) Hint(ctx context.Context, b *domain.Board, max domain.StrategyTier) (domain.Hint, bool, error) {
 	if u.Hinter == nil {
 		return domain.Hint{}, false, errNotConfigured
@@ -50,13 +64,41 @@ func (u *Service) Hint(ctx context.Context, b *domain.Board, max domain.Strategy
 	return u.Hinter.Hint(ctx, b, max)
 }
 
+// HintFrom runs the hinter against the player's candidates instead of ones
+// computed from the placed values.
+func (u *Service) HintFrom(ctx context.Context, b *domain.Board, cands *domain.Marks, max domain.StrategyTier) (domain.Hint, bool, error) {
+	if u.Hinter == nil {
+		return domain.Hint{}, false, errNotConfigured
+	}
+	return u.Hinter.HintFrom(ctx, b, cands, max)
+}
+
 // Persistence
 func (u *Service) Save(ctx context.Context, p *domain.Puzzle) error {
 	if u.Storage == nil {
 		return errNotConfigured
 	}
+	// Completion is derived from the board rather than trusted from the
+	// client; without a validator it cannot be, so it is never set.
+	p.Completed = false
+	if u.Validator != nil {
+		ok, _, err := u.Validator.Validate(ctx, &p.Board)
+		p.Completed = err == nil && ok && isFull(&p.Board)
+	}
 	return u.Storage.Save(ctx, p)
 }
+
+func isFull(b *domain.Board) bool {
+	for r := 0; r < 9; r++ {
+		for c := 0; c < 9; c++ {
+			if b.Values[r][c] == 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 func (u *Service) Load(ctx context.Context, id string) (*domain.Puzzle, error) {
 	if u.Storage == nil {
 		return nil, errNotConfigured
@@ -68,4 +110,122 @@ func (u *Service) List(ctx context.Context) ([]domain.PuzzleMeta, error) {
 		return nil, errNotConfigured
 	}
 	return u.Storage.List(ctx)
-}
\ No newline at end of file
+}
+
+// Update changes the name, notes, tags or favorite flag of a stored puzzle.
+func (u *Service) Update(ctx context.Context, id string, patch domain.PuzzlePatch) (*domain.Puzzle, error) {
+	if u.Storage == nil {
+		return nil, errNotConfigured
+	}
+	return u.Storage.Update(ctx, id, patch)
+}
+
+// Delete removes a stored puzzle. It returns until when Restore can bring
+// it back, or the zero time when storage keeps no trash.
+func (u *Service) Delete(ctx context.Context, id string) (time.Time, error) {
+	if u.Storage == nil {
+		return time.Time{}, errNotConfigured
+	}
+	return u.Storage.Delete(ctx, id)
+}
+
+// Restore undoes Delete while the puzzle is still in the trash.
+func (u *Service) Restore(ctx context.Context, id string) (*domain.Puzzle, error) {
+	if u.Storage == nil {
+		return nil, errNotConfigured
+	}
+	return u.Storage.Restore(ctx, id)
+}
+
+// CheckSolution compares the user's entries with the unique solution of the
+// givens (cells marked Fixed). Since that solution is unique, the board can be
+// completed exactly when no entry conflicts or differs from it.
+func (u *Service) CheckSolution(ctx context.Context, b *domain.Board) (domain.Check, error) {
+	if u.Solver == nil || u.Validator == nil {
+		return domain.Check{}, errNotConfigured
+	}
+	_, conflicts, err := u.Validator.Validate(ctx, b)
+	if err != nil {
+		return domain.Check{}, err
+	}
+	givens, n := givensOf(b)
+	if n == 0 {
+		return domain.Check{}, domain.Errorf(domain.KindInvalid, "no givens marked fixed to check against")
+	}
+	sol, err := u.uniqueSolution(ctx, &givens)
+	if err != nil {
+		return domain.Check{}, err
+	}
+	var wrong []domain.CellCoord
+	for r := 0; r < 9; r++ {
+		for c := 0; c < 9; c++ {
+			if v := b.Values[r][c]; v != 0 && !b.Fixed[r][c] && v != sol.Values[r][c] {
+				wrong = append(wrong, domain.CellCoord{Row: r, Col: c})
+			}
+		}
+	}
+	return domain.Check{
+		Conflicts: conflicts,
+		Wrong:     wrong,
+		Solvable:  len(conflicts) == 0 && len(wrong) == 0,
+	}, nil
+}
+
+// CheckMarks reports pencil marks that miss the solution digit or keep a
+// digit a placed peer already rules out. The solution comes from the fixed
+// givens, or from the whole board when none are marked.
+func (u *Service) CheckMarks(ctx context.Context, b *domain.Board, marks *domain.Marks) (domain.MarksReport, error) {
+	if u.Solver == nil || u.Validator == nil {
+		return domain.MarksReport{}, errNotConfigured
+	}
+	givens, n := givensOf(b)
+	if n == 0 {
+		givens = domain.Board{Values: b.Values}
+	}
+	sol, err := u.uniqueSolution(ctx, &givens)
+	if err != nil {
+		return domain.MarksReport{}, err
+	}
+	return hint.CheckMarks(b, marks, sol), nil
+}
+
+// givensOf returns b restricted to its fixed cells and the number of givens.
+func givensOf(b *domain.Board) (domain.Board, int) {
+	givens := domain.Board{Fixed: b.Fixed}
+	n := 0
+	for r := 0; r < 9; r++ {
+		for c := 0; c < 9; c++ {
+			if b.Fixed[r][c] {
+				givens.Values[r][c] = b.Values[r][c]
+				n++
+			}
+		}
+	}
+	return givens, n
+}
+
+// uniqueSolution solves clues after checking they are consistent and admit
+// exactly one solution.
+func (u *Service) uniqueSolution(ctx context.Context, clues *domain.Board) (*domain.Board, error) {
+	ok, conflicts, err := u.Validator.Validate(ctx, clues)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		e := domain.Errorf(domain.KindInvalid, "givens conflict with each other")
+		e.Details = map[string]any{"conflicts": conflicts}
+		return nil, e
+	}
+	sol, _, err := u.Solver.Solve(ctx, clues)
+	if err != nil {
+		return nil, err
+	}
+	unique, _, err := u.Solver.Unique(ctx, clues)
+	if err != nil {
+		return nil, err
+	}
+	if !unique {
+		return nil, domain.Errorf(domain.KindNotUnique, "givens do not have a unique solution")
+	}
+	return sol, nil
+}
@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/infrastructure/storage"
+	"svw.info/sudoku/internal/solver"
+	"svw.info/sudoku/internal/validator"
+)
+
+var givens = [9][9]uint8{
+	{5, 3, 0, 0, 7, 0, 0, 0, 0},
+	{6, 0, 0, 1, 9, 5, 0, 0, 0},
+	{0, 9, 8, 0, 0, 0, 0, 6, 0},
+	{8, 0, 0, 0, 6, 0, 0, 0, 3},
+	{4, 0, 0, 8, 0, 3, 0, 0, 1},
+	{7, 0, 0, 0, 2, 0, 0, 0, 6},
+	{0, 6, 0, 0, 0, 0, 2, 8, 0},
+	{0, 0, 0, 4, 1, 9, 0, 0, 5},
+	{0, 0, 0, 0, 8, 0, 0, 7, 9},
+}
+
+func fixedBoard() *domain.Board {
+	b := &domain.Board{Values: givens}
+	for r := 0; r < 9; r++ {
+		for c := 0; c < 9; c++ {
+			b.Fixed[r][c] = givens[r][c] != 0
+		}
+	}
+	return b
+}
+
+func TestCheckSolution(t *testing.T) {
+	ctx := context.Background()
+	uc := NewService(solver.NewDLXSolver(), nil, validator.New(), nil, nil)
+
+	b := fixedBoard()
+	b.Values[0][2] = 4 // correct
+	chk, err := uc.CheckSolution(ctx, b)
+	if err != nil {
+		t.Fatalf("CheckSolution: %v", err)
+	}
+	if len(chk.Wrong) != 0 || !chk.Solvable {
+		t.Fatalf("correct entry reported wrong: %+v", chk)
+	}
+
+	b.Values[0][3] = 2 // wrong (solution is 6) but no duplicate yet
+	chk, err = uc.CheckSolution(ctx, b)
+	if err != nil {
+		t.Fatalf("CheckSolution: %v", err)
+	}
+	if len(chk.Conflicts) != 0 {
+		t.Fatalf("unexpected conflicts: %v", chk.Conflicts)
+	}
+	if len(chk.Wrong) != 1 || chk.Wrong[0] != (domain.CellCoord{Row: 0, Col: 3}) || chk.Solvable {
+		t.Fatalf("wrong entry not detected: %+v", chk)
+	}
+
+	if _, err := uc.CheckSolution(ctx, &domain.Board{Values: givens}); err == nil {
+		t.Fatalf("expected error without fixed givens")
+	}
+}
+
+func TestCheckMarks(t *testing.T) {
+	ctx := context.Background()
+	uc := NewService(solver.NewDLXSolver(), nil, validator.New(), nil, nil)
+	b := fixedBoard()
+
+	var marks domain.Marks
+	marks.Toggle(0, 2, 1) // r1c3 solution is 4: 4 missing
+	marks.Toggle(0, 2, 2)
+	marks.Toggle(0, 3, 6) // correct candidate...
+	marks.Toggle(0, 3, 5) // ...plus 5, already in row 1
+	rep, err := uc.CheckMarks(ctx, b, &marks)
+	if err != nil {
+		t.Fatalf("CheckMarks: %v", err)
+	}
+	if len(rep.Missing) != 1 || rep.Missing[0] != (domain.Candidate{Row: 0, Col: 2, Digit: 4}) {
+		t.Fatalf("missing = %+v", rep.Missing)
+	}
+	if len(rep.Impossible) != 1 || rep.Impossible[0] != (domain.Candidate{Row: 0, Col: 3, Digit: 5}) {
+		t.Fatalf("impossible = %+v", rep.Impossible)
+	}
+}
+
+func TestUpdateDeleteRestore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	st := storage.NewFS(dir)
+	st.Trash = time.Hour
+	uc := NewService(nil, nil, nil, nil, st)
+
+	// A save from before the difficulty folders, which Update moves.
+	if err := os.WriteFile(filepath.Join(dir, "old.json"), []byte(`{"id":"old","name":"Old","tags":["a"]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	name, fav := "Renamed", true
+	p, err := uc.Update(ctx, "old", domain.PuzzlePatch{Name: &name, Favorite: &fav})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != name || !p.Favorite || len(p.Tags) != 1 || p.Difficulty != domain.Medium {
+		t.Errorf("updated puzzle = %+v", p)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "medium", "old.json")); err != nil {
+		t.Errorf("legacy file not moved: %v", err)
+	}
+
+	// Changing the difficulty moves the file rather than copying it.
+	p.Difficulty = domain.Hard
+	if err := uc.Save(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	if metas, _ := uc.List(ctx); len(metas) != 1 || metas[0].Difficulty != domain.Hard || !metas[0].Favorite {
+		t.Errorf("List after a difficulty change = %+v", metas)
+	}
+
+	until, err := uc.Delete(ctx, "old")
+	if err != nil || time.Until(until) < 59*time.Minute {
+		t.Fatalf("Delete = %v, %v", until, err)
+	}
+	if _, err := uc.Load(ctx, "old"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Load after Delete: %v", err)
+	}
+	if p, err := uc.Restore(ctx, "old"); err != nil || p.Difficulty != domain.Hard || p.Name != name {
+		t.Errorf("Restore = %+v, %v", p, err)
+	}
+	if _, err := uc.Restore(ctx, "old"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("second Restore: %v", err)
+	}
+
+	st.Trash = 0
+	if until, err := uc.Delete(ctx, "old"); err != nil || !until.IsZero() {
+		t.Errorf("Delete without trash = %v, %v", until, err)
+	}
+	if _, err := uc.Restore(ctx, "old"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Restore without trash: %v", err)
+	}
+	if _, err := uc.Delete(ctx, "../old"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Delete outside the store: %v", err)
+	}
+}
+
+func TestSaveDerivesCompletedAndKeepsPlayState(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	uc := NewService(nil, nil, validator.New(), nil, storage.NewFS(dir))
+	const solved = "534678912672195348198342567859761423426853791713924856961537284287419635345286179"
+	var full domain.Board
+	for i, ch := range solved {
+		full.Values[i/9][i%9] = uint8(ch - '0')
+	}
+
+	clash := full
+	clash.Values[0][0] = 3 // r1c2 holds 3 too
+	marks := &domain.Marks{}
+	marks.Toggle(0, 2, 1)
+	marks.Toggle(0, 2, 4)
+	p := &domain.Puzzle{ID: "play", Board: *fixedBoard(), Marks: marks, ElapsedNanos: int64(90 * time.Second), Mistakes: 2, Completed: true}
+	if err := uc.Save(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	got, err := NewService(nil, nil, nil, nil, storage.NewFS(dir)).Load(ctx, "play")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Completed || got.Marks == nil || *got.Marks != *marks || got.ElapsedNanos != p.ElapsedNanos || got.Mistakes != 2 || got.Board != p.Board {
+		t.Errorf("reloaded play state = %+v", got)
+	}
+
+	for _, tc := range []struct {
+		name      string
+		uc        *Service
+		board     domain.Board
+		completed bool
+	}{
+		{"solved", uc, full, true},
+		{"full with a conflict", uc, clash, false},
+		{"solved without a validator", NewService(nil, nil, nil, nil, storage.NewFS(dir)), full, false},
+	} {
+		p := &domain.Puzzle{ID: "done", Board: tc.board, Completed: !tc.completed}
+		if err := tc.uc.Save(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+		got, err := uc.Load(ctx, "done")
+		if err != nil {
+			t.Fatal(err)
+		}
+		metas, _ := uc.List(ctx)
+		listed := false
+		for _, m := range metas {
+			listed = listed || m.ID == "done" && m.Completed
+		}
+		if p.Completed != tc.completed || got.Completed != tc.completed || listed != tc.completed {
+			t.Errorf("%s: Completed %v, reloaded %v, listed %v; want %v", tc.name, p.Completed, got.Completed, listed, tc.completed)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+
+	"svw.info/sudoku/internal/codec"
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/generator"
+	"svw.info/sudoku/internal/ocr"
+)
+
+// ErrNoPuzzles reports an import whose input holds no puzzle at all.
+var ErrNoPuzzles = domain.Errorf(domain.KindInvalid, "no puzzles found")
+
+// Import decodes every puzzle in r, in whatever text format it is written,
+// and saves each under a fresh ID. Like ImportCollection, each puzzle is
+// rated by the hinter when one is configured and is Medium otherwise. It
+// returns the saved puzzles and the detected format; nothing is saved when
+// the input does not parse.
+func (u *Service) Import(ctx context.Context, r io.Reader) ([]domain.Puzzle, codec.Format, error) {
+	if u.Storage == nil {
+		return nil, "", errNotConfigured
+	}
+	ps, f, err := codec.Decode(r)
+	if err != nil {
+		return nil, f, parseError(err)
+	}
+	if len(ps) == 0 {
+		return nil, f, ErrNoPuzzles
+	}
+	now := time.Now().UnixNano()
+	for i := range ps {
+		if err := ctx.Err(); err != nil {
+			return ps[:i], f, err
+		}
+		ps[i].ID = strconv.FormatInt(now+int64(i), 10)
+		ps[i].CreatedAt = now
+		ps[i].Difficulty = domain.Medium
+		if u.Hinter != nil {
+			rt, err := generator.Rate(ctx, u.Hinter, &ps[i].Board, domain.StrategyUniqueness)
+			if err != nil {
+				return ps[:i], f, err
+			}
+			ps[i].Difficulty = rt.Difficulty()
+		}
+		if err := u.Save(ctx, &ps[i]); err != nil {
+			return ps[:i], f, err
+		}
+	}
+	return ps, f, nil
+}
+
+// parseError classifies a decoding failure as invalid input, with the
+// position of a syntax error in the details.
+func parseError(err error) error {
+	var ce *codec.Error
+	if !errors.As(err, &ce) {
+		return err
+	}
+	return &domain.Error{
+		Kind:    domain.KindInvalid,
+		Message: err.Error(),
+		Details: map[string]any{"line": ce.Line, "column": ce.Col},
+		Err:     err,
+	}
+}
+
+// ImageImport is a board read from a screenshot, for the player to confirm
+// before it is saved or played.
+type ImageImport struct {
+	ocr.Result
+	Uncertain []domain.CellCoord // cells read with less than ocr.Uncertain confidence
+	Valid     bool
+	Conflicts []domain.CellCoord
+	Unique    bool // exactly one solution; only checked when Valid
+}
+
+// ImportImage recognises the board in a PNG, JPEG or GIF screenshot and
+// checks that its givens do not conflict and have exactly one solution.
+// Nothing is saved.
+func (u *Service) ImportImage(ctx context.Context, r io.Reader) (*ImageImport, error) {
+	if u.Solver == nil || u.Validator == nil {
+		return nil, errNotConfigured
+	}
+	res, err := ocr.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	out := &ImageImport{Result: *res}
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			if res.Confidence[row][col] < ocr.Uncertain {
+				out.Uncertain = append(out.Uncertain, domain.CellCoord{Row: row, Col: col})
+			}
+		}
+	}
+	if out.Valid, out.Conflicts, err = u.Validator.Validate(ctx, &res.Board); err != nil {
+		return nil, err
+	}
+	if out.Valid {
+		if out.Unique, _, err = u.Solver.Unique(ctx, &res.Board); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// Export writes the stored puzzles with the given IDs to w in format f, or
+// every stored puzzle when ids is empty. All puzzles are loaded before
+// anything is written, so a missing ID leaves w untouched.
+func (u *Service) Export(ctx context.Context, ids []string, f codec.Format, w io.Writer) error {
+	if u.Storage == nil {
+		return errNotConfigured
+	}
+	ps, err := u.loadAll(ctx, ids)
+	if err != nil {
+		return err
+	}
+	return codec.Encode(w, f, ps)
+}
+
+// loadAll loads the puzzles with the given IDs in order, or every stored
+// puzzle when ids is empty.
+func (u *Service) loadAll(ctx context.Context, ids []string) ([]domain.Puzzle, error) {
+	if len(ids) == 0 {
+		metas, err := u.Storage.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range metas {
+			ids = append(ids, m.ID)
+		}
+	}
+	ps := make([]domain.Puzzle, 0, len(ids))
+	for _, id := range ids {
+		p, err := u.Storage.Load(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		ps = append(ps, *p)
+	}
+	return ps, nil
+}
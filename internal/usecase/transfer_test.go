@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"svw.info/sudoku/internal/codec"
+	"svw.info/sudoku/internal/domain"
+	"svw.info/sudoku/internal/generator"
+	"svw.info/sudoku/internal/hint"
+	"svw.info/sudoku/internal/infrastructure/storage"
+	"svw.info/sudoku/internal/solver"
+	"svw.info/sudoku/internal/validator"
+)
+
+func TestImportRatesDifficulty(t *testing.T) {
+	ctx := context.Background()
+	b := domain.Board{Values: givens}
+	h := hint.NewPipeline()
+	rt, err := generator.Rate(ctx, h, &b, domain.StrategyUniqueness)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		name   string
+		hinter *hint.Pipeline
+		want   domain.Difficulty
+	}{
+		{"rated", h, rt.Difficulty()},
+		{"no hinter", nil, domain.Medium},
+	} {
+		uc := NewService(solver.NewBacktrackingSolver(), nil, validator.New(), nil, storage.NewFS(t.TempDir()))
+		if c.hinter != nil {
+			uc.Hinter = c.hinter
+		}
+		ps, _, err := uc.Import(ctx, strings.NewReader(codec.Line(&b)))
+		if err != nil || len(ps) != 1 {
+			t.Fatalf("%s: Import = %v, %v", c.name, ps, err)
+		}
+		p, err := uc.Storage.Load(ctx, ps[0].ID)
+		if err != nil || p.Difficulty != c.want {
+			t.Fatalf("%s: stored difficulty = %v (%v), want %v", c.name, p, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+
+	"svw.info/sudoku/internal/domain"
+)
+
+// Contradictions reports empty cells without candidates, digits that have no
+// place left in a house, and houses where two cells are forced to the same
+// digit. It only looks at placed values, so it runs in a single pass.
+func (v *FastValidator) Contradictions(ctx context.Context, b *domain.Board) ([]domain.Contradiction, error) {
+	g, _ := domain.NewGrid(b)
+	var out []domain.Contradiction
+	for cell := 0; cell < 81; cell++ {
+		if g.Values[cell] == 0 && g.Cands[cell] == 0 {
+			cc := domain.Coord(cell)
+			out = append(out, domain.Contradiction{
+				Kind:    domain.NoCandidates,
+				Cells:   []domain.CellCoord{cc},
+				Message: fmt.Sprintf("r%dc%d has no candidates left", cc.Row+1, cc.Col+1),
+			})
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for h := range domain.Houses {
+		house := domain.HouseAt(h)
+		var placed domain.Digits
+		for _, cell := range domain.Houses[h] {
+			placed = placed.With(g.Values[cell])
+		}
+		for d := uint8(1); d <= 9; d++ {
+			if placed.Has(d) {
+				continue
+			}
+			spots := 0
+			var forced []domain.CellCoord // cells whose only candidate is d
+			for _, cell := range domain.Houses[h] {
+				m := g.Cands[cell]
+				if !m.Has(d) {
+					continue
+				}
+				spots++
+				if m.Count() == 1 {
+					forced = append(forced, domain.Coord(cell))
+				}
+			}
+			if spots == 0 {
+				out = append(out, domain.Contradiction{
+					Kind:    domain.NoPlace,
+					House:   &house,
+					Digit:   d,
+					Message: fmt.Sprintf("%d has no place left in %s", d, house),
+				})
+			}
+			if len(forced) > 1 {
+				out = append(out, domain.Contradiction{
+					Kind:    domain.SameSingle,
+					Cells:   forced,
+					House:   &house,
+					Digit:   d,
+					Message: fmt.Sprintf("%d cells in %s can only be %d", len(forced), house, d),
+				})
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,65 @@
+package validator
+
+import (
+	"context"
+	"testing"
+
+	"svw.info/sudoku/internal/domain"
+)
+
+func TestContradictions(t *testing.T) {
+	ctx := context.Background()
+	v := New()
+
+	// r1c1 sees 1..8 in its row and 9 in its column: no candidates left.
+	var b domain.Board
+	for c := 1; c < 9; c++ {
+		b.Values[0][c] = uint8(c)
+	}
+	b.Values[4][0] = 9
+	got, err := v.Contradictions(ctx, &b)
+	if err != nil {
+		t.Fatalf("Contradictions: %v", err)
+	}
+	found := false
+	for _, ct := range got {
+		if ct.Kind == domain.NoCandidates && ct.Cells[0] == (domain.CellCoord{}) {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("missing no-candidates for r1c1: %+v", got)
+	}
+
+	// 5 is blocked from every empty cell of box 1 (top-left) by rows and columns.
+	b = domain.Board{}
+	b.Values[0][0], b.Values[0][1], b.Values[0][2] = 1, 2, 3
+	b.Values[1][5] = 5
+	b.Values[2][7] = 5
+	got, _ = v.Contradictions(ctx, &b)
+	found = false
+	for _, ct := range got {
+		if ct.Kind == domain.NoPlace && ct.Digit == 5 && *ct.House == (domain.House{Kind: domain.HouseBox, Index: 0}) {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("missing no-place for 5 in box 1: %+v", got)
+	}
+
+	// An empty board has no dead ends.
+	if got, _ := v.Contradictions(ctx, &domain.Board{}); len(got) != 0 {
+		t.Fatalf("empty board reported %+v", got)
+	}
+}
+
+func BenchmarkContradictions(b *testing.B) {
+	ctx := context.Background()
+	v := New()
+	var board domain.Board
+	board.Values[0] = [9]uint8{5, 3, 0, 0, 7, 0, 0, 0, 0}
+	board.Values[4] = [9]uint8{4, 0, 0, 8, 0, 3, 0, 0, 1}
+	for i := 0; i < b.N; i++ {
+		_, _ = v.Contradictions(ctx, &board)
+	}
+}
@@ -10,58 +10,26 @@ type FastValidator struct{}
 
 func New() *FastValidator { return &FastValidator{} }
 
+// Validate reports every cell that repeats a digit already seen earlier in
+// its row, column or box (houses scanned in that order). A value above 9 is
+// an error: it has no bit in domain.Digits to collide on.
 func (v *FastValidator) Validate(ctx context.Context, b *domain.Board) (bool, []domain.CellCoord, error) {
 	conf := make([]domain.CellCoord, 0, 8)
-	// rows
-	for r := 0; r < 9; r++ {
-		m := 0
-		for c := 0; c < 9; c++ {
-			val := b.Values[r][c]
+	for h := range domain.Houses {
+		var seen domain.Digits
+		for _, cell := range domain.Houses[h] {
+			val := b.Values[cell/9][cell%9]
 			if val == 0 {
 				continue
 			}
-			bit := 1 << val
-			if m&bit != 0 {
-				conf = append(conf, domain.CellCoord{Row: r, Col: c})
+			if val > 9 {
+				return false, nil, domain.Errorf(domain.KindInvalid, "invalid digit %d at r%dc%d", val, cell/9+1, cell%9+1)
 			}
-			m |= bit
-		}
-	}
-	// cols
-	for c := 0; c < 9; c++ {
-		m := 0
-		for r := 0; r < 9; r++ {
-			val := b.Values[r][c]
-			if val == 0 {
-				continue
-			}
-			bit := 1 << val
-			if m&bit != 0 {
-				conf = append(conf, domain.CellCoord{Row: r, Col: c})
-			}
-			m |= bit
-		}
-	}
-	// boxes
-	for br := 0; br < 3; br++ {
-		for bc := 0; bc < 3; bc++ {
-			m := 0
-			for dr := 0; dr < 3; dr++ {
-				for dc := 0; dc < 3; dc++ {
-					r := br*3 + dr
-					c := bc*3 + dc
-					val := b.Values[r][c]
-					if val == 0 {
-						continue
-					}
-					bit := 1 << val
-					if m&bit != 0 {
-						conf = append(conf, domain.CellCoord{Row: r, Col: c})
-					}
-					m |= bit
-				}
+			if seen.Has(val) {
+				conf = append(conf, domain.Coord(cell))
 			}
+			seen = seen.With(val)
 		}
 	}
 	return len(conf) == 0, conf, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,18 @@
+package validator
+
+import (
+	"context"
+	"testing"
+
+	"svw.info/sudoku/internal/domain"
+)
+
+func TestValidateRejectsBigValues(t *testing.T) {
+	var b domain.Board
+	b.Values[2][4] = 16 // 1<<16 would overflow the candidate set
+	b.Values[2][5] = 16
+	_, _, err := New().Validate(context.Background(), &b)
+	if domain.KindOf(err) != domain.KindInvalid {
+		t.Fatalf("Validate = %v, want an invalid_input error", err)
+	}
+}
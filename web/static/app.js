@@ -4,12 +4,22 @@ document.addEventListener("DOMContentLoaded",()=>{
   const diffSel=document.getElementById("diff-select");
   const btnSolve=document.getElementById("solve");
   const btnValidate=document.getElementById("validate");
+  const btnCheck=document.getElementById("check");
+  const btnCheckNotes=document.getElementById("check-notes");
   const btnHint=document.getElementById("hint");
   const btnSave=document.getElementById("save");
   const btnLoad=document.getElementById("load");
+  const btnDelete=document.getElementById("delete");
   const nameInput=document.getElementById("name-input");
   const notesInput=document.getElementById("notes-input");
   const autoCand=document.getElementById("auto-candidates");
+  const hintFromNotes=document.getElementById("hint-from-notes");
+  const hintTier=document.getElementById("hint-tier");
+  const statusEl=document.getElementById("status");
+  const btnReplay=document.getElementById("replay");
+  const replaySpeed=document.getElementById("replay-speed");
+  const replayInfo=document.getElementById("replay-info");
+  const hintInfo=document.getElementById("hint-info");
 
   // --- state ---
   let sel={r:0,c:0};
@@ -25,6 +35,19 @@ document.addEventListener("DOMContentLoaded",()=>{
   const AUTOSAVE_DEBOUNCE_MS=2000;
   const AUTOSAVE_INTERVAL_MS=10000;
 
+  // Server-side game session (move log); ?game=<id> resumes it in any browser
+  let gameId = new URLSearchParams(location.search).get("game") || localStorage.getItem("sudoku.gameId") || "";
+  let moveQueue = Promise.resolve();
+
+  // Play state persisted with saves
+  let elapsedMs=0;
+  let mistakes=0;
+  let completed=false;
+  let replaying=false;
+  // Per-browser id so repeated hint requests escalate on the server
+  let hintSession=localStorage.getItem("sudoku.hintSession");
+  if(!hintSession){ hintSession=Math.random().toString(36).slice(2); localStorage.setItem("sudoku.hintSession", hintSession); }
+
   // --- helpers to build cells ---
   function mkCell(r,c){
     const d=document.createElement("div");
@@ -45,17 +68,26 @@ document.addEventListener("DOMContentLoaded",()=>{
   function setFixed(r,c,fx){cell(r,c).classList.toggle("fixed",!!fx);}
   function setVal(r,c,v){
     const el=cell(r,c);
-    if(el.classList.contains("fixed")) return;
+    if(replaying || el.classList.contains("fixed")) return;
     pushUndo(); // capture state before mutation
     const n = Math.max(0, Math.min(9, v|0));
+    if(getVal(r,c)!==n){
+      sendMove(n===0?"clear":"set", r, c, n);
+      if(n!==0 && !allowedAt(r,c,n)) mistakes++;
+    }
     el.dataset.val=String(n);
+    el.classList.remove("wrong");
     if(n===0){ renderCell(r,c); } else { el.dataset.notes=""; renderCell(r,c); }
+    completed=isSolved();
+    renderStatus();
+    scheduleDeadEndCheck();
     markDirty();
   }
   function toggleNote(r,c,d){
     const el=cell(r,c);
-    if(el.classList.contains("fixed")) return;
+    if(replaying || el.classList.contains("fixed")) return;
     pushUndo();
+    sendMove("note", r, c, parseInt(d,10));
     let s=el.dataset.notes||"";
     if(s.includes(d)){ s=s.replace(d,""); } else { s=[...s,d].sort().join(""); }
     el.dataset.notes=s;
@@ -122,6 +154,7 @@ document.addEventListener("DOMContentLoaded",()=>{
       const fx = !!(fixed?.[r]?.[c]);
       cell(r,c).dataset.val=String(v);
       cell(r,c).dataset.notes="";
+      cell(r,c).classList.remove("wrong");
       setFixed(r,c,fx);
     } }
     renderAll();
@@ -136,6 +169,17 @@ document.addEventListener("DOMContentLoaded",()=>{
     for(let dr=0;dr<3;dr++){ for(let dc=0;dc<3;dc++){ if(getVal(br+dr,bc+dc)===v) return false; } }
     return true;
   }
+  function isSolved(){
+    for(let r=0;r<9;r++){ for(let c=0;c<9;c++){
+      const v=getVal(r,c);
+      if(v===0) return false;
+      cell(r,c).dataset.val="0";
+      const ok=allowedAt(r,c,v);
+      cell(r,c).dataset.val=String(v);
+      if(!ok) return false;
+    } }
+    return true;
+  }
   function candidatesString(r,c){
     if(getVal(r,c)!==0) return "";
     let s="";
@@ -143,6 +187,36 @@ document.addEventListener("DOMContentLoaded",()=>{
     return s;
   }
 
+  // --- timer & status ---
+  function renderStatus(){
+    if(!statusEl) return;
+    const s=Math.floor(elapsedMs/1000);
+    const mm=String(Math.floor(s/60)).padStart(2,"0"), ss=String(s%60).padStart(2,"0");
+    statusEl.textContent=`Time ${mm}:${ss} · Mistakes ${mistakes}`+(completed?" · Solved!":"");
+  }
+  function resetPlayState(p){
+    elapsedMs=Math.floor((p?.elapsedNanos||0)/1e6);
+    mistakes=p?.mistakes||0;
+    completed=!!p?.completed;
+    renderStatus();
+  }
+  setInterval(()=>{
+    if(completed || document.hidden) return;
+    elapsedMs+=1000;
+    renderStatus();
+  }, 1000);
+
+  function notesToMarks(notes){
+    let any=false;
+    const m=notes.map(row=>row.map(s=>{
+      let bits=0;
+      for(const ch of s){ bits|=1<<parseInt(ch,10); }
+      if(bits) any=true;
+      return bits;
+    }));
+    return any ? m : undefined;
+  }
+
   // --- Undo/Redo ---
   function snapshot(){
     return {
@@ -168,7 +242,7 @@ document.addEventListener("DOMContentLoaded",()=>{
       if(s.meta.difficulty) diffSel.value = s.meta.difficulty;
     }
     renderAll();
-    clearClass("conflict"); clearInlineOutlines();
+    clearClass("conflict"); clearClass("wrong"); clearInlineOutlines();
   }
   function pushUndo(){
     // prevent pushing identical immediate states by checking last snapshot's board reference (cheap heuristic)
@@ -179,6 +253,7 @@ document.addEventListener("DOMContentLoaded",()=>{
     redoStack.length = 0;
   }
   function undo(){
+    if(gameId) return gameCall("undo");
     if(undoStack.length===0) return;
     const current = snapshot();
     const state = undoStack.pop();
@@ -188,6 +263,7 @@ document.addEventListener("DOMContentLoaded",()=>{
     markDirty(); // content changed
   }
   function redo(){
+    if(gameId) return gameCall("redo");
     if(redoStack.length===0) return;
     const current = snapshot();
     const state = redoStack.pop();
@@ -200,6 +276,7 @@ document.addEventListener("DOMContentLoaded",()=>{
   // --- keyboard ---
   document.addEventListener("keydown",e=>{
     const k=e.key;
+    if(replaying) return;
     // Undo/Redo shortcuts
     if(e.ctrlKey && !e.shiftKey && k==="z"){ e.preventDefault(); return undo(); }
     if((e.ctrlKey && k==="y") || (e.ctrlKey && e.shiftKey && k==="Z")){ e.preventDefault(); return redo(); }
@@ -237,8 +314,164 @@ document.addEventListener("DOMContentLoaded",()=>{
     return res.json();
   }
 
+  // --- game sessions ---
+  function marksToNotes(marks){
+    return [...Array(9)].map((_,r)=>[...Array(9)].map((_,c)=>{
+      let s=""; const m=marks?.[r]?.[c]||0;
+      for(let v=1;v<=9;v++){ if(m&(1<<v)) s+=String(v); }
+      return s;
+    }));
+  }
+  function setGameId(id){
+    gameId=id||"";
+    if(gameId) localStorage.setItem("sudoku.gameId", gameId); else localStorage.removeItem("sudoku.gameId");
+    const u=new URL(location.href);
+    if(gameId) u.searchParams.set("game", gameId); else u.searchParams.delete("game");
+    history.replaceState(null, "", u);
+  }
+  function applyGame(g){
+    setBoard(g.board.board, g.start.fixed);
+    setNotes(marksToNotes(g.marks));
+    clearClass("conflict"); clearInlineOutlines();
+  }
+  async function startGame(body){
+    try{
+      const res=await fetch("/api/games",{method:"POST",headers:{"Content-Type":"application/json"},body:JSON.stringify(body)});
+      const data=await res.json();
+      setGameId(data.game ? data.game.id : "");
+    }catch(e){ console.warn("Start game failed",e); setGameId(""); }
+  }
+  // Moves are queued so the server log keeps the order they were made in.
+  function sendMove(kind,r,c,value){
+    if(!gameId) return;
+    const id=gameId;
+    moveQueue=moveQueue.then(()=>api(`/api/games/${id}/moves`,{kind,row:r,col:c,value}))
+      .then(data=>{ if(data.error) console.warn("Move rejected:",data.error); })
+      .catch(e=>console.warn("Move failed",e));
+  }
+  function gameCall(action){
+    const id=gameId;
+    moveQueue=moveQueue.then(()=>api(`/api/games/${id}/${action}`))
+      .then(data=>{ if(data.game){ applyGame(data.game); markDirty(); } })
+      .catch(e=>console.warn(action+" failed",e));
+    return moveQueue;
+  }
+  async function resumeGame(){
+    if(!gameId) return;
+    try{
+      const res=await fetch(`/api/games/${gameId}`);
+      const data=await res.json();
+      if(data.game){ applyGame(data.game); setGameId(gameId); } else { setGameId(""); }
+    }catch(e){ console.warn("Resume failed",e); }
+  }
+
+  // --- replay ---
+  const sleep=ms=>new Promise(res=>setTimeout(res,ms));
+  function fmtMs(ms){
+    const s=Math.round(ms/1000);
+    return `${Math.floor(s/60)}:${String(s%60).padStart(2,"0")}`;
+  }
+  function showReplayInfo(a){
+    if(!replayInfo || !a) return;
+    const slow=[...(a.regions||[])].sort((x,y)=>y.durationMs-x.durationMs)[0];
+    const pause=(a.longestPauses||[])[0];
+    const parts=[`Total ${fmtMs(a.durationMs)}`];
+    if(slow && slow.durationMs>0) parts.push(`most time in box ${slow.box+1} (${fmtMs(slow.durationMs)})`);
+    if(pause && pause.durationMs>0) parts.push(`longest pause ${fmtMs(pause.durationMs)} before move #${pause.index+1}`);
+    parts.push(`${(a.undone||[]).length} move(s) undone`);
+    replayInfo.textContent=parts.join(" · ");
+  }
+  // Plays the server move log back from the starting board; long gaps are capped at 5s.
+  async function playReplay(){
+    if(replaying){ replaying=false; return; }
+    if(!gameId){ alert("No game session to replay."); return; }
+    let rp;
+    try{
+      const res=await fetch(`/api/games/${gameId}/replay`);
+      const data=await res.json();
+      if(!data.replay){ alert("Replay failed: "+(data.error||"unknown")); return; }
+      rp=data.replay;
+    }catch(e){ alert("Replay error: "+e); return; }
+    showReplayInfo(rp.analytics);
+    replaying=true;
+    btnReplay.textContent="Stop";
+    const applied=[], redo=[];
+    const draw=()=>{
+      setBoard(rp.start.board, rp.start.fixed);
+      const notes=[...Array(9)].map(()=>Array(9).fill(""));
+      for(const i of applied){
+        const m=rp.steps[i];
+        if(m.kind==="set"){ cell(m.row,m.col).dataset.val=String(m.value); notes[m.row][m.col]=""; }
+        else if(m.kind==="clear"){ cell(m.row,m.col).dataset.val="0"; }
+        else if(m.kind==="note"){
+          const d=String(m.value), cur=notes[m.row][m.col];
+          notes[m.row][m.col]=cur.includes(d) ? cur.replace(d,"") : [...cur,d].sort().join("");
+        }
+      }
+      setNotes(notes);
+      renderAll();
+    };
+    draw();
+    for(const s of rp.steps){
+      const speed=parseFloat(replaySpeed?.value)||1;
+      await sleep(Math.min(s.deltaMs,5000)/speed);
+      if(!replaying) break;
+      if(s.kind==="undo"){ if(applied.length) redo.push(applied.pop()); }
+      else if(s.kind==="redo"){ if(redo.length) applied.push(redo.pop()); }
+      else { applied.push(s.index); redo.length=0; }
+      draw();
+      clearInlineOutlines();
+      if(s.kind!=="undo" && s.kind!=="redo"){ cell(s.row,s.col).style.outline=s.undone?"2px dashed crimson":"2px solid steelblue"; }
+    }
+    replaying=false;
+    btnReplay.textContent="Replay";
+    await resumeGame();
+  }
+  btnReplay?.addEventListener("click",()=>playReplay());
+
   function markConflicts(conf){ clearClass("conflict"); if(!conf) return; for(const p of conf){ cell(p.row,p.col).classList.add("conflict"); } }
-  function markHint(cells,msg){ clearInlineOutlines(); if(!cells||!cells.length){ alert("No simple hint found."); return; } for(const p of cells){ cell(p.row,p.col).style.outline="2px dashed orange"; } if(msg) console.log(msg); }
+  // Dead ends (no candidates / no place for a digit) are checked shortly after each move.
+  let deadEndTimer=null;
+  function scheduleDeadEndCheck(){
+    if(deadEndTimer) clearTimeout(deadEndTimer);
+    deadEndTimer=setTimeout(checkDeadEnds, 300);
+  }
+  async function checkDeadEnds(){
+    try{
+      const data=await api("/api/validate",{board:getBoard(),mode:"deep"});
+      clearClass("dead");
+      const list=data.contradictions||[];
+      for(const ct of list){
+        for(const p of (ct.cells||[])) cell(p.row,p.col).classList.add("dead");
+        if(ct.house) for(const p of houseCells(ct.house)) if(getVal(p.row,p.col)===0) cell(p.row,p.col).classList.add("dead");
+      }
+      if(list.length) console.log("Dead end: "+list[0].message);
+    }catch(e){ console.warn("Dead-end check failed",e); }
+  }
+  function houseCells(h){
+    const out=[];
+    for(let j=0;j<9;j++){
+      if(h.kind==="row") out.push({row:h.index,col:j});
+      else if(h.kind==="col") out.push({row:j,col:h.index});
+      else out.push({row:Math.floor(h.index/3)*3+Math.floor(j/3), col:(h.index%3)*3+j%3});
+    }
+    return out;
+  }
+  function markWrong(wrong){ clearClass("wrong"); if(!wrong) return; for(const p of wrong){ cell(p.row,p.col).classList.add("wrong"); } }
+  // chain links as text, e.g. "r1c1(2)⇒r1c5(2)"; ⇒ strong, → weak
+  function linksText(links){
+    if(!links || !links.length) return "";
+    const cand=c=>`r${c.row+1}c${c.col+1}(${c.digit})`;
+    return " · links: " + links.map(l=>cand(l.from)+(l.kind==="strong"?"⇒":"→")+cand(l.to)).join(", ");
+  }
+  function markHint(hint,used){
+    clearInlineOutlines();
+    for(const p of (hint.cells||[])){ cell(p.row,p.col).style.outline="2px dashed orange"; }
+    if(hintInfo){
+      const more = hint.level && hint.level<4 ? " (press Hint again for more)" : "";
+      hintInfo.textContent = (hint.message||"") + more + (used ? ` · hints used: ${used}` : "") + linksText(hint.links);
+    }
+  }
 
   // --- actions ---
   btnValidate?.addEventListener("click",async()=>{
@@ -249,6 +482,30 @@ document.addEventListener("DOMContentLoaded",()=>{
     }catch(e){ console.error("Validate failed",e); }
   });
 
+  btnCheck?.addEventListener("click",async()=>{
+    try{
+      const data=await api("/api/validate",{board:getBoard(),fixed:getFixed(),mode:"solution"});
+      if(data.error){ alert("Check failed: "+data.error); return; }
+      markConflicts(data.conflicts);
+      markWrong(data.wrong);
+      if(data.ok) console.log("OK: no mistakes so far");
+      else if(!data.solvable) console.log("Board can no longer be completed");
+    }catch(e){ console.error("Check failed",e); }
+  });
+
+  btnCheckNotes?.addEventListener("click",async()=>{
+    try{
+      const data=await api("/api/marks/check",{board:getBoard(),fixed:getFixed(),marks:notesToMarks(getNotes())||[]});
+      if(data.error){ alert("Notes check failed: "+data.error); return; }
+      clearInlineOutlines();
+      for(const m of (data.missing||[])) cell(m.row,m.col).style.outline="2px solid crimson";
+      for(const m of (data.impossible||[])) if(!cell(m.row,m.col).style.outline) cell(m.row,m.col).style.outline="2px dashed crimson";
+      const n=(data.missing||[]).length, k=(data.impossible||[]).length;
+      if(data.ok) console.log("Notes OK");
+      else console.log(`Notes: ${n} cell(s) missing the right candidate, ${k} impossible candidate(s) left`);
+    }catch(e){ console.error("Notes check failed",e); }
+  });
+
   btnSolve?.addEventListener("click",async()=>{
     try{
       const data=await api("/api/solve",{board:getBoard()});
@@ -266,8 +523,10 @@ document.addEventListener("DOMContentLoaded",()=>{
         localStorage.removeItem("sudoku.currentId");
         nameInput.value=""; notesInput.value="";
         setBoard(data.board.board, data.board.fixed);
+        resetPlayState(null);
         clearClass("conflict"); clearInlineOutlines();
         undoStack.length=0; redoStack.length=0;
+        await startGame({board:{board:data.board.board, fixed:data.board.fixed}});
         markDirty(); // fresh board is unsaved
       }
       else{ alert("Generate failed: "+(data.error||"unknown")); }
@@ -289,9 +548,11 @@ document.addEventListener("DOMContentLoaded",()=>{
   btnHint?.addEventListener("click",()=>doHint());
   async function doHint(){
     try{
-      const data=await api("/api/hint",{board:getBoard(),maxTier:"singles"});
-      if(data.found && data.hint){ markHint(data.hint.cells, data.hint.message); }
-      else { alert("No simple hint found."); clearInlineOutlines(); }
+      const req={board:getBoard(),maxTier:hintTier?.value||"singles",session:hintSession,gameId:gameId||undefined,fallback:true};
+      if(hintFromNotes?.checked) req.candidates=notesToMarks(getNotes());
+      const data=await api("/api/hint",req);
+      if(data.found && data.hint){ markHint(data.hint, data.hintsUsed); }
+      else { alert("No hint found: the board may contain a mistake."); clearInlineOutlines(); }
     }catch(e){ alert("Hint error: "+e); }
   }
 
@@ -312,15 +573,31 @@ document.addEventListener("DOMContentLoaded",()=>{
   }
 
   // Save helpers (manual + autosave)
+  // A new puzzle gets its id before the first save, so autosaves racing it
+  // (or the unload beacon) overwrite one puzzle instead of each adding one.
+  function ensureId(){
+    if(!currentId){
+      currentId=String(BigInt(Date.now())*1000000n+BigInt(Math.floor(Math.random()*1e6)));
+      localStorage.setItem("sudoku.currentId", currentId);
+    }
+    return currentId;
+  }
+  function savePayload(){
+    return {
+      id: ensureId(),
+      name: (nameInput?.value||"").trim(),
+      notes: (notesInput?.value||"").trim(),
+      // omit difficulty to avoid server enum mismatch; storage defaults to 'medium' if absent
+      board:{board:getBoard(), fixed:getFixed()},
+      marks: notesToMarks(getNotes()),
+      elapsedNanos: elapsedMs*1e6,
+      mistakes,
+      completed
+    };
+  }
   async function savePuzzle({silent}={silent:true}){
     try{
-      const payload={
-        id: currentId || "",
-        name: (nameInput?.value||"").trim(),
-        notes: (notesInput?.value||"").trim(),
-        // omit difficulty to avoid server enum mismatch; storage defaults to 'medium' if absent
-        board:{board:getBoard(), fixed:getFixed()}
-      };
+      const payload=savePayload();
       const res=await fetch("/api/save",{method:"POST",headers:{"Content-Type":"application/json"},body:JSON.stringify(payload)});
       const data=await res.json();
       if(data.id){
@@ -351,31 +628,35 @@ document.addEventListener("DOMContentLoaded",()=>{
   window.addEventListener("beforeunload", (e)=>{
     if(dirty){
       // try to save but don't block the unload
-      navigator.sendBeacon && navigator.sendBeacon("/api/save", new Blob([JSON.stringify({
-        id: currentId || "",
-        name: (nameInput?.value||"").trim(),
-        notes: (notesInput?.value||"").trim(),
-        board:{board:getBoard(), fixed:getFixed()}
-      })], {type:"application/json"}));
+      navigator.sendBeacon && navigator.sendBeacon("/api/save", new Blob([JSON.stringify(savePayload())], {type:"application/json"}));
     }
   });
 
   // Manual save button now uses shared save
   btnSave?.addEventListener("click",()=>savePuzzle({silent:false}));
 
+  // pickPuzzle asks for one of the saved puzzles; "" when there is none or
+  // the player cancels.
+  async function pickPuzzle(verb){
+    const lr=await fetch("/api/list");
+    const l=await lr.json();
+    const items=(l.puzzles||[]);
+    if(items.length===0){ alert("No saved puzzles."); return ""; }
+    const lines=items.map(p=> p.name ? `${p.id} — ${p.name}` : p.id);
+    const choice=prompt("Enter id to "+verb+":\n"+lines.join("\n"));
+    if(!choice) return "";
+    return choice.split(" — ")[0].trim();
+  }
+
   btnLoad?.addEventListener("click",async()=>{
     try{
-      const lr=await fetch("/api/list");
-      const l=await lr.json();
-      const items=(l.puzzles||[]);
-      if(items.length===0){ alert("No saved puzzles."); return; }
-      const lines=items.map(p=> p.name ? `${p.id} — ${p.name}` : p.id);
-      const choice=prompt("Enter id to load:\n"+lines.join("\n"));
-      if(!choice) return;
-      const id=choice.split(" — ")[0].trim();
+      const id=await pickPuzzle("load");
+      if(!id) return;
       const data=await api("/api/load",{id});
       if(data.puzzle){
         setBoard(data.puzzle.board.board, data.puzzle.board.fixed);
+        setNotes(marksToNotes(data.puzzle.marks));
+        resetPlayState(data.puzzle);
         nameInput.value=data.puzzle.name||"";
         notesInput.value=data.puzzle.notes||"";
         // difficulty handling is optional; keep current selector value if not provided
@@ -383,14 +664,35 @@ document.addEventListener("DOMContentLoaded",()=>{
         localStorage.setItem("sudoku.currentId", currentId);
         clearClass("conflict"); clearInlineOutlines();
         undoStack.length=0; redoStack.length=0;
+        await startGame({puzzleId:currentId});
         dirty=false;
       } else { alert("Load failed: "+(data.error||"unknown")); }
     }catch(e){ alert("Load error: "+e); }
   });
 
+  btnDelete?.addEventListener("click",async()=>{
+    try{
+      const id=await pickPuzzle("delete");
+      if(!id) return;
+      const url="/api/v1/puzzles/"+encodeURIComponent(id);
+      const data=await (await fetch(url,{method:"DELETE"})).json();
+      if(!data.id){ alert("Delete failed: "+(data.message||"unknown")); return; }
+      // Keep autosave from writing the deleted puzzle back.
+      const wasCurrent=id===currentId;
+      if(wasCurrent){ currentId=""; localStorage.removeItem("sudoku.currentId"); dirty=false; }
+      if(data.restorableUntil && confirm("Deleted "+id+". Undo?")){
+        const back=await (await fetch(url+"/restore",{method:"POST"})).json();
+        if(!back.id){ alert("Restore failed: "+(back.message||"unknown")); return; }
+        if(wasCurrent){ currentId=back.id; localStorage.setItem("sudoku.currentId", currentId); }
+      }
+    }catch(e){ alert("Delete error: "+e); }
+  });
+
   autoCand?.addEventListener("change",()=>{ renderAll(); });
 
   // initial paint
   renderAll();
   highlight();
+  renderStatus();
+  resumeGame();
 });
\ No newline at end of file